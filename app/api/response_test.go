@@ -40,3 +40,35 @@ func TestErrorResponse(t *testing.T) {
 		assert.JSONEq(t, expected, recorder.Body.String(), "Response body does not match expected")
 	})
 }
+
+func TestCreatedResponse(t *testing.T) {
+	t.Run("http201 json response", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		CreatedResponse(recorder, map[string]string{"code": "PROD009"})
+
+		assert.Equal(t, http.StatusCreated, recorder.Code, "Expected status code 201 Created")
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), "Expected Content-Type to be application/json")
+		assert.JSONEq(t, `{"code":"PROD009"}`, recorder.Body.String(), "Response body does not match expected")
+	})
+}
+
+func TestNoContentResponse(t *testing.T) {
+	t.Run("http204 without body", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		NoContentResponse(recorder)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code, "Expected status code 204 No Content")
+		assert.Empty(t, recorder.Body.String(), "Expected empty response body")
+	})
+}
+
+func TestUnprocessableEntityResponse(t *testing.T) {
+	t.Run("http422 json response", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		UnprocessableEntityResponse(recorder, map[string]int{"invalid": 1})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code, "Expected status code 422 Unprocessable Entity")
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), "Expected Content-Type to be application/json")
+		assert.JSONEq(t, `{"invalid":1}`, recorder.Body.String(), "Response body does not match expected")
+	})
+}
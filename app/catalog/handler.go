@@ -1,19 +1,81 @@
 package catalog
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/mytheresa/go-hiring-challenge/app/api"
 	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
 	"github.com/shopspring/decimal"
 )
 
+// Response is a page of the catalog. Total is left out when the request passes withTotal=false.
+// NextCursor is set when a page without offset is full and continues the listing in keyset mode.
+// Facets holds the counts requested with facets=category,price.
 type Response struct {
-	Products []interface{} `json:"products"`
-	Total    int64         `json:"total"`
+	Products   []interface{}             `json:"products"`
+	Total      *int64                    `json:"total,omitempty"`
+	NextCursor string                    `json:"nextCursor,omitempty"`
+	Facets     *repository.ProductFacets `json:"facets,omitempty"`
 }
 
+// SearchResponse is a page of search results, best matches first.
+type SearchResponse struct {
+	Results []repository.SearchResult `json:"results"`
+	Total   *int64                    `json:"total,omitempty"`
+}
+
+// ExportRecord is a product, or one of its variants, in an NDJSON export. It has the shape of an
+// import row, so an export can be imported again.
+type ExportRecord struct {
+	Code         string           `json:"code"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Brand        string           `json:"brand"`
+	Price        decimal.Decimal  `json:"price"`
+	Category     string           `json:"category,omitempty"`
+	Categories   []string         `json:"categories,omitempty"`
+	Status       string           `json:"status"`
+	SKU          string           `json:"sku,omitempty"`
+	VariantName  string           `json:"variantName,omitempty"`
+	VariantPrice *decimal.Decimal `json:"variantPrice,omitempty"`
+	Options      []ExportOption   `json:"options,omitempty"`
+}
+
+// ExportOption is the value a variant takes for an option of its product.
+type ExportOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// exportColumns are the CSV export columns before one option:<name> column per option name.
+// The categories column lists the further categories separated by categorySeparator.
+var exportColumns = []string{"code", "name", "description", "brand", "price", "category", "categories", "status", "sku", "variantName", "variantPrice"}
+
+// categorySeparator separates the category codes of the CSV categories column.
+const categorySeparator = "|"
+
+// exportFlushRows is the number of exported rows sent to the client at a time.
+const exportFlushRows = 100
+
+// optionParamPrefix starts the query parameters that filter by a variant option, named by the
+// rest of the parameter, e.g. option.material=wool.
+const optionParamPrefix = "option."
+
+// optionShorthands are query parameters that filter by the variant option of the same name
+// without the prefix.
+var optionShorthands = []string{"size", "color"}
+
 type CatalogHandler struct {
 	repo repository.ProductsInterface
 }
@@ -26,7 +88,17 @@ func NewCatalogHandler(r repository.ProductsInterface) *CatalogHandler {
 
 func (h *CatalogHandler) HandleGetAll(w http.ResponseWriter, r *http.Request) {
 	// Process filters from request
-	filter := h.processFilters(r)
+	filter, err := h.processFilters(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	facets, err := parseFacets(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Get products from repository
 	products, total, err := h.repo.GetProducts(filter)
@@ -38,37 +110,402 @@ func (h *CatalogHandler) HandleGetAll(w http.ResponseWriter, r *http.Request) {
 	// Return the products as a JSON response
 	response := Response{
 		Products: make([]interface{}, len(products)),
-		Total:    total,
+	}
+	if !filter.SkipTotal {
+		response.Total = &total
+	}
+	for i := range products {
+		if err := products[i].ApplyCurrency(filter.Currency); err != nil {
+			api.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	}
+	// Offset pagination has no use for a cursor; pages without an offset may start keyset mode
+	if len(products) > 0 && len(products) == filter.Limit && !r.URL.Query().Has("offset") {
+		response.NextCursor = repository.ProductCursor(products[len(products)-1], filter.Sort).Encode()
+	}
+	if len(facets) > 0 {
+		if response.Facets, err = h.repo.GetProductFacets(filter, facets); err != nil {
+			api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	}
 
 	for i := range products {
+		products[i].ApplyVariantPrices()
 		response.Products[i] = products[i]
 	}
 
 	api.OKResponse(w, response)
 }
 
-func (h *CatalogHandler) processFilters(r *http.Request) repository.ProductsFilter {
+// HandleSearch searches products by code, name, brand, description, variant name and SKU. The
+// catalog filters narrow the results; results are ordered by relevance and paginated by offset.
+func (h *CatalogHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	terms := strings.TrimSpace(r.URL.Query().Get("q"))
+	if terms == "" {
+		api.ErrorResponse(w, http.StatusBadRequest, "Search query q is required")
+		return
+	}
+
+	// Process filters from request
+	filter, err := h.processFilters(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.After != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "cursor is not supported for search, use offset")
+		return
+	}
+
+	// Search products in repository
+	results, total, err := h.repo.SearchProducts(terms, filter)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := SearchResponse{
+		Results: results,
+	}
+	if !filter.SkipTotal {
+		response.Total = &total
+	}
+
+	for i := range response.Results {
+		if err := response.Results[i].ApplyCurrency(filter.Currency); err != nil {
+			api.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		response.Results[i].ApplyVariantPrices()
+	}
+
+	api.OKResponse(w, response)
+}
+
+// HandleExport streams every product and variant matching the catalog filters as CSV or NDJSON,
+// chosen by format. Prices are exported in the base currency only, so a currency is refused, and
+// pagination parameters are ignored. Rows are flushed to the client as they are read, so the
+// export starts at once and memory use does not grow with the catalog.
+func (h *CatalogHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "csv" && format != "ndjson" {
+		api.ErrorResponse(w, http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+
+	// Process filters from request, exporting everything that matches
+	filter, err := h.processFilters(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Currency != models.BaseCurrency {
+		api.ErrorResponse(w, http.StatusBadRequest, "currency is not supported for export, prices are exported in "+models.BaseCurrency)
+		return
+	}
+	filter.After = nil
+	filter.Offset = 0
+	filter.Limit = 0
+	filter.SkipTotal = true
+
+	var export exporter
+	if format == "csv" {
+		// The CSV header needs every option name up front
+		names, err := h.repo.GetProductOptionNames(filter)
+		if err != nil {
+			api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		export = newCSVExporter(w, names)
+	} else {
+		export = &ndjsonExporter{encoder: json.NewEncoder(w)}
+	}
+
+	controller := http.NewResponseController(w)
+	rows, started := 0, false
+	err = h.repo.ExportProducts(filter, func(row repository.ExportRow) error {
+		if !started {
+			started = true
+			startExport(w, format)
+		}
+		if err := export.write(row); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			if err := export.flush(); err != nil {
+				return err
+			}
+			return ignoreUnsupported(controller.Flush())
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// The status is sent already; cutting the stream short is all that is left
+		log.Printf("export failed after %d rows: %v", rows, err)
+		return
+	}
+
+	if !started {
+		startExport(w, format)
+	}
+	if err := export.flush(); err != nil {
+		log.Printf("export failed after %d rows: %v", rows, err)
+	}
+}
+
+// startExport sends the headers of an export download.
+func startExport(w http.ResponseWriter, format string) {
+	contentType := "text/csv; charset=utf-8"
+	if format == "ndjson" {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="catalog.`+format+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// ignoreUnsupported drops the error of writers that cannot flush, which send the whole response at the end.
+func ignoreUnsupported(err error) error {
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// exporter writes export rows in a format.
+type exporter interface {
+	write(row repository.ExportRow) error
+	// flush passes the rows written so far on to the response.
+	flush() error
+}
+
+type csvExporter struct {
+	writer  *csv.Writer
+	options []string
+	header  bool
+}
+
+func newCSVExporter(w io.Writer, options []string) *csvExporter {
+	return &csvExporter{writer: csv.NewWriter(w), options: options}
+}
+
+func (e *csvExporter) write(row repository.ExportRow) error {
+	if !e.header {
+		e.header = true
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	record := []string{
+		row.Code, row.Name, row.Description, row.Brand, row.Price.StringFixed(2), row.Category,
+		strings.Join(row.Categories, categorySeparator), row.Status, row.SKU, row.VariantName, "",
+	}
+	if row.VariantPrice.Valid {
+		record[10] = row.VariantPrice.Decimal.StringFixed(2)
+	}
+	for _, name := range e.options {
+		value := ""
+		for _, option := range row.Options {
+			if option.Name == name {
+				value = option.Value
+			}
+		}
+		record = append(record, value)
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExporter) writeHeader() error {
+	header := slices.Clone(exportColumns)
+	for _, name := range e.options {
+		header = append(header, "option:"+name)
+	}
+	return e.writer.Write(header)
+}
+
+func (e *csvExporter) flush() error {
+	// An empty export still has its header
+	if !e.header {
+		e.header = true
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// ndjsonExporter writes each row as it comes; the encoder does not buffer.
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) write(row repository.ExportRow) error {
+	record := ExportRecord{
+		Code:        row.Code,
+		Name:        row.Name,
+		Description: row.Description,
+		Brand:       row.Brand,
+		Price:       row.Price,
+		Category:    row.Category,
+		Categories:  row.Categories,
+		Status:      row.Status,
+		SKU:         row.SKU,
+		VariantName: row.VariantName,
+	}
+	if row.VariantPrice.Valid {
+		record.VariantPrice = &row.VariantPrice.Decimal
+	}
+	for _, option := range row.Options {
+		record.Options = append(record.Options, ExportOption{Name: option.Name, Value: option.Value})
+	}
+	return e.encoder.Encode(record)
+}
+
+func (e *ndjsonExporter) flush() error {
+	return nil
+}
+
+// parseFacets reads the comma separated facets parameter, rejecting unknown facets.
+func parseFacets(r *http.Request) ([]string, error) {
+	param := r.URL.Query().Get("facets")
+	if param == "" {
+		return nil, nil
+	}
+
+	var facets []string
+	for _, facet := range strings.Split(param, ",") {
+		facet = strings.TrimSpace(facet)
+		if !slices.Contains(repository.ProductFacetNames, facet) {
+			return nil, fmt.Errorf("unknown facet %q", facet)
+		}
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
+
+func (h *CatalogHandler) processFilters(r *http.Request) (repository.ProductsFilter, error) {
 	// Parse pagination parameters
 	offset, limit := common.ParseOffsetLimit(r)
 
+	// Parse sort order
+	sort, err := common.ParseSort(r, repository.ProductSortFields)
+	if err != nil {
+		return repository.ProductsFilter{}, err
+	}
+
+	// Parse cursor; keyset pagination replaces the offset
+	after, err := common.ParseCursor(r, sort)
+	if err != nil {
+		return repository.ProductsFilter{}, err
+	}
+	if after != nil && r.URL.Query().Has("offset") {
+		return repository.ProductsFilter{}, errors.New("cursor and offset cannot be combined")
+	}
+
+	// Parse currency; prices are filtered, sorted and returned in it
+	currency, err := common.ParseCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		return repository.ProductsFilter{}, err
+	}
+
+	// Unpublished products are only listed for admin requests, which may filter by status.
+	// Deleted products are only listed when admin requests ask for them.
+	scope := repository.PublishedProducts
+	if common.IsAdmin(r) {
+		scope = repository.AllProducts
+		if r.URL.Query().Get("includeDeleted") == "true" {
+			scope = repository.AllProductsWithDeleted
+		}
+	}
+	statuses := r.URL.Query()["status"]
+	for _, status := range statuses {
+		if !slices.Contains(models.ProductStatuses, status) {
+			return repository.ProductsFilter{}, fmt.Errorf("unknown status %q", status)
+		}
+	}
+
 	// Build filter
 	filter := repository.ProductsFilter{
-		Offset: offset,
-		Limit:  limit,
+		Scope:     scope,
+		Statuses:  statuses,
+		Sort:      sort,
+		After:     after,
+		Offset:    offset,
+		Limit:     limit,
+		SkipTotal: r.URL.Query().Get("withTotal") == "false",
+		Currency:  currency,
 	}
 
-	// Parse category filter
-	if categoryCode := r.URL.Query().Get("category"); categoryCode != "" {
-		filter.CategoryCode = &categoryCode
+	// Parse category filter, matching any of the given categories unless categoryMatch=all
+	for _, categoryCode := range r.URL.Query()["category"] {
+		if categoryCode != "" {
+			filter.CategoryCodes = append(filter.CategoryCodes, categoryCode)
+		}
+	}
+	if len(filter.CategoryCodes) > 0 {
+		filter.CategoryMatch = repository.CategoryMatchAny
+		if r.URL.Query().Get("categoryMatch") == repository.CategoryMatchAll {
+			filter.CategoryMatch = repository.CategoryMatchAll
+		}
+		filter.IncludeSubcategories = r.URL.Query().Get("includeSubcategories") == "true"
+	}
+
+	// Parse code prefix filter
+	if codePrefix := r.URL.Query().Get("code"); codePrefix != "" {
+		filter.CodePrefix = &codePrefix
 	}
 
-	// Parse max price filter
-	if maxPriceStr := r.URL.Query().Get("priceLessThan"); maxPriceStr != "" {
-		if maxPrice, err := decimal.NewFromString(maxPriceStr); err == nil {
+	// Parse price range filters; priceBetween=min,max sets both bounds
+	filter.MinPrice = common.ParseDecimalParam(r, "priceGreaterThan")
+	filter.MaxPrice = common.ParseDecimalParam(r, "priceLessThan")
+	if between := strings.Split(r.URL.Query().Get("priceBetween"), ","); len(between) == 2 {
+		minPrice, minErr := decimal.NewFromString(strings.TrimSpace(between[0]))
+		maxPrice, maxErr := decimal.NewFromString(strings.TrimSpace(between[1]))
+		if minErr == nil && maxErr == nil {
+			filter.MinPrice = &minPrice
 			filter.MaxPrice = &maxPrice
 		}
 	}
 
-	return filter
+	// Parse variant effective price filters
+	filter.VariantMinPrice = common.ParseDecimalParam(r, "variantPriceGreaterThan")
+	filter.VariantMaxPrice = common.ParseDecimalParam(r, "variantPriceLessThan")
+
+	// Parse variant option filters, e.g. option.material=wool&size=M&size=L
+	query := r.URL.Query()
+	for _, param := range slices.Sorted(maps.Keys(query)) {
+		name, ok := strings.CutPrefix(param, optionParamPrefix)
+		if !ok && slices.Contains(optionShorthands, param) {
+			name, ok = param, true
+		}
+		if !ok {
+			continue
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			return repository.ProductsFilter{}, fmt.Errorf("option filter %q has no option name", param)
+		}
+		if filter.Options == nil {
+			filter.Options = make(map[string][]string)
+		}
+		filter.Options[name] = append(filter.Options[name], query[param]...)
+	}
+
+	// Parse variants presence filter
+	filter.HasVariants = common.ParseBoolParam(r, "hasVariants")
+
+	// Parse availability filter
+	filter.InStock = common.ParseBoolParam(r, "inStock")
+
+	// Parse sale filter
+	filter.OnSale = common.ParseBoolParam(r, "onSale")
+
+	return filter, nil
 }
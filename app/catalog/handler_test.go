@@ -2,10 +2,14 @@ package catalog
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/repository"
 	"github.com/mytheresa/go-hiring-challenge/models"
 	"github.com/shopspring/decimal"
@@ -26,7 +30,95 @@ func (m *MockProductsRepository) GetProducts(filter repository.ProductsFilter) (
 	return args.Get(0).([]models.Product), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockProductsRepository) GetProductByCode(code string) (*models.Product, error) {
+func (m *MockProductsRepository) GetProductByCode(code string, scope repository.ProductScope) (*models.Product, error) {
+	args := m.Called(code, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductsRepository) SearchProducts(terms string, filter repository.ProductsFilter) ([]repository.SearchResult, int64, error) {
+	args := m.Called(terms, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]repository.SearchResult), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProductsRepository) GetProductFacets(filter repository.ProductsFilter, facets []string) (*repository.ProductFacets, error) {
+	args := m.Called(filter, facets)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ProductFacets), args.Error(1)
+}
+
+func (m *MockProductsRepository) GetProductOptionNames(filter repository.ProductsFilter) ([]string, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockProductsRepository) ExportProducts(filter repository.ProductsFilter, fn func(repository.ExportRow) error) error {
+	args := m.Called(filter, fn)
+	if rows, ok := args.Get(0).([]repository.ExportRow); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductsRepository) CreateProduct(product *models.Product, actor string) error {
+	args := m.Called(product, actor)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) UpdateProduct(product *models.Product, actor string) error {
+	args := m.Called(product, actor)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) ChangeProductStatus(code, status string, publishAt, unpublishAt *time.Time) (*models.Product, error) {
+	args := m.Called(code, status, publishAt, unpublishAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductsRepository) GetPriceHistory(code string, scope repository.ProductScope) ([]models.PriceChange, error) {
+	args := m.Called(code, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceChange), args.Error(1)
+}
+
+func (m *MockProductsRepository) SetProductPrice(code, currency string, amount decimal.Decimal, actor string) (*models.ProductPrice, error) {
+	args := m.Called(code, currency, amount, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductPrice), args.Error(1)
+}
+
+func (m *MockProductsRepository) DeleteProductPrice(code, currency, actor string) error {
+	args := m.Called(code, currency, actor)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) DeleteProduct(code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) RestoreProduct(code string) (*models.Product, error) {
 	args := m.Called(code)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -34,6 +126,16 @@ func (m *MockProductsRepository) GetProductByCode(code string) (*models.Product,
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
+func (m *MockProductsRepository) AttachCategories(code string, categoryCodes []string) error {
+	args := m.Called(code, categoryCodes)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) DetachCategory(code, categoryCode string) error {
+	args := m.Called(code, categoryCode)
+	return args.Error(0)
+}
+
 func TestHandleGetAll(t *testing.T) {
 	t.Run("returns products with category and total count", func(t *testing.T) {
 		mockRepo := new(MockProductsRepository)
@@ -64,7 +166,46 @@ func TestHandleGetAll(t *testing.T) {
 		var response Response
 		json.NewDecoder(rec.Body).Decode(&response)
 		assert.Len(t, response.Products, 1)
-		assert.Equal(t, int64(1), response.Total)
+		assert.Equal(t, int64(1), *response.Total)
+		assert.Empty(t, response.NextCursor)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("resolves inherited variant prices", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		products := []models.Product{
+			{
+				Code:  "PROD001",
+				Price: decimal.NewFromFloat(10.99),
+				Variants: []models.Variant{
+					{SKU: "SKU001A", Price: decimal.NewNullDecimal(decimal.NewFromFloat(11.99))},
+					{SKU: "SKU001B"},
+				},
+			},
+		}
+
+		mockRepo.On("GetProducts", mock.Anything).Return(products, int64(1), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Products []models.Product `json:"products"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Products, 1)
+		variants := response.Products[0].Variants
+		assert.True(t, variants[0].EffectivePrice.Equal(decimal.NewFromFloat(11.99)))
+		assert.False(t, variants[0].PriceInherited)
+		assert.True(t, variants[1].EffectivePrice.Equal(decimal.NewFromFloat(10.99)))
+		assert.True(t, variants[1].PriceInherited)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -74,7 +215,8 @@ func TestHandleGetAll(t *testing.T) {
 		handler := NewCatalogHandler(mockRepo)
 
 		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
-			return filter.CategoryCode != nil && *filter.CategoryCode == "SHOES"
+			return assert.ObjectsAreEqual([]string{"SHOES"}, filter.CategoryCodes) &&
+				filter.CategoryMatch == repository.CategoryMatchAny
 		})).Return([]models.Product{}, int64(0), nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/catalog?category=SHOES", nil)
@@ -86,6 +228,41 @@ func TestHandleGetAll(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("filters by several categories with all semantics", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual([]string{"ACCESSORIES", "SALE"}, filter.CategoryCodes) &&
+				filter.CategoryMatch == repository.CategoryMatchAll
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?category=ACCESSORIES&category=SALE&categoryMatch=all", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters by category including subcategories", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual([]string{"CLOTHING"}, filter.CategoryCodes) && filter.IncludeSubcategories
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?category=CLOTHING&includeSubcategories=true", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("filters by price less than", func(t *testing.T) {
 		mockRepo := new(MockProductsRepository)
 		handler := NewCatalogHandler(mockRepo)
@@ -103,15 +280,197 @@ func TestHandleGetAll(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("supports pagination with offset and limit", func(t *testing.T) {
+	t.Run("filters by price greater than", func(t *testing.T) {
 		mockRepo := new(MockProductsRepository)
 		handler := NewCatalogHandler(mockRepo)
 
 		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
-			return filter.Offset == 5 && filter.Limit == 20
+			return filter.MinPrice != nil && filter.MinPrice.Equal(decimal.NewFromFloat(10)) && filter.MaxPrice == nil
 		})).Return([]models.Product{}, int64(0), nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/catalog?offset=5&limit=20", nil)
+		req := httptest.NewRequest(http.MethodGet, "/catalog?priceGreaterThan=10", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters by price between", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.MinPrice != nil && filter.MinPrice.Equal(decimal.NewFromFloat(10)) &&
+				filter.MaxPrice != nil && filter.MaxPrice.Equal(decimal.NewFromFloat(20.5))
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?priceBetween=10,20.50", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ignores malformed price between", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.MinPrice == nil && filter.MaxPrice == nil
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?priceBetween=10", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters by code prefix, variants and variant price", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.CodePrefix != nil && *filter.CodePrefix == "PROD00" &&
+				filter.HasVariants != nil && *filter.HasVariants &&
+				filter.VariantMaxPrice != nil && filter.VariantMaxPrice.Equal(decimal.NewFromFloat(50))
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?code=PROD00&hasVariants=true&variantPriceLessThan=50", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters by variant options", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual(map[string][]string{
+				"size":  {"M", "L"},
+				"color": {"black"},
+			}, filter.Options)
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?size=M&size=L&color=black", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters by any named option", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual(map[string][]string{
+				"material": {"wool"},
+				"width":    {"wide"},
+				"size":     {"M", "L"},
+			}, filter.Options)
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?option.Material=wool&option.width=wide&option.size=M&size=L", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for an option filter without a name", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?option.=wool", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("filters by availability", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.InStock != nil && *filter.InStock
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?inStock=true", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns prices in the requested currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		products := []models.Product{
+			{
+				Code:   "PROD001",
+				Price:  decimal.NewFromFloat(10.99),
+				Prices: []models.ProductPrice{{Currency: "GBP", Amount: decimal.NewFromFloat(9.49)}},
+				Variants: []models.Variant{
+					{SKU: "SKU001B"},
+				},
+			},
+		}
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Currency == "GBP"
+		})).Return(products, int64(1), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?currency=gbp", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Products []models.Product `json:"products"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Products, 1)
+		assert.Equal(t, "GBP", response.Products[0].Currency)
+		assert.True(t, response.Products[0].Price.Equal(decimal.NewFromFloat(9.49)))
+		assert.Equal(t, "GBP", response.Products[0].Variants[0].Currency)
+		assert.True(t, response.Products[0].Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(9.49)))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reads the currency from the Accept-Currency header", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Currency == "USD"
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		req.Header.Set("Accept-Currency", "USD")
 		rec := httptest.NewRecorder()
 
 		handler.HandleGetAll(rec, req)
@@ -119,4 +478,608 @@ func TestHandleGetAll(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("returns 400 for unsupported currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?currency=JPY", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("returns 422 when a variant has no price in the currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		products := []models.Product{
+			{
+				Code:   "PROD001",
+				Price:  decimal.NewFromFloat(10.99),
+				Prices: []models.ProductPrice{{Currency: "CHF", Amount: decimal.NewFromFloat(10.49)}},
+				Variants: []models.Variant{
+					{SKU: "SKU001A", Price: decimal.NewNullDecimal(decimal.NewFromFloat(11.99))},
+				},
+			},
+		}
+		mockRepo.On("GetProducts", mock.Anything).Return(products, int64(1), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?currency=CHF", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters by active sale and shows sale prices", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		products := []models.Product{
+			{
+				Code:  "PROD002",
+				Price: decimal.NewFromFloat(20),
+				Sales: []models.SalePrice{{Currency: "EUR", Price: decimal.NewFromFloat(15)}},
+				Variants: []models.Variant{
+					{SKU: "SKU002A"},
+					{SKU: "SKU002B", Price: decimal.NewNullDecimal(decimal.NewFromFloat(22))},
+				},
+			},
+		}
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.OnSale != nil && *filter.OnSale
+		})).Return(products, int64(1), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?onSale=true", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Products []models.Product `json:"products"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+		product := response.Products[0]
+		assert.True(t, product.Price.Equal(decimal.NewFromFloat(15)))
+		assert.True(t, product.OriginalPrice.Equal(decimal.NewFromFloat(20)))
+		assert.Equal(t, 25, product.DiscountPercent)
+		// The inheriting variant shares the product sale, the other keeps its own price
+		assert.True(t, product.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(15)))
+		assert.Equal(t, 25, product.Variants[0].DiscountPercent)
+		assert.True(t, product.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(22)))
+		assert.Nil(t, product.Variants[1].OriginalPrice)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lists published products unless the request is admin", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Scope == repository.PublishedProducts
+		})).Return([]models.Product{}, int64(0), nil).Once()
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Scope == repository.AllProducts &&
+				len(filter.Statuses) == 2 && filter.Statuses[0] == models.StatusDraft
+		})).Return([]models.Product{}, int64(0), nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		rec := httptest.NewRecorder()
+		handler.HandleGetAll(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		req = common.WithAdmin(httptest.NewRequest(http.MethodGet, "/catalog?status=draft&status=archived", nil))
+		rec = httptest.NewRecorder()
+		handler.HandleGetAll(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lists deleted products when asked for", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Scope == repository.AllProductsWithDeleted
+		})).Return([]models.Product{}, int64(0), nil).Once()
+
+		req := common.WithAdmin(httptest.NewRequest(http.MethodGet, "/catalog?includeDeleted=true", nil))
+		rec := httptest.NewRecorder()
+		handler.HandleGetAll(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ignores admin and includeDeleted parameters outside admin requests", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Scope == repository.PublishedProducts
+		})).Return([]models.Product{}, int64(0), nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?admin=true&includeDeleted=true", nil)
+		rec := httptest.NewRecorder()
+		handler.HandleGetAll(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown status", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?status=live", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("sorts by allowed fields", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual([]common.SortField{
+				{Name: "price", Desc: true},
+				{Name: "code"},
+			}, filter.Sort)
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?sort=-price,code", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown sort field", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?sort=-id", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("returns next cursor for a full page and accepts it back", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		products := []models.Product{
+			{ID: 7, Code: "PROD007", Price: decimal.NewFromFloat(18.2)},
+			{ID: 4, Code: "PROD004", Price: decimal.NewFromFloat(15)},
+		}
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.After == nil
+		})).Return(products, int64(8), nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?sort=-price&limit=2", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response Response
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.NotEmpty(t, response.NextCursor)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.After != nil && filter.After.ID == 4 &&
+				assert.ObjectsAreEqual([]string{"15"}, filter.After.Values)
+		})).Return([]models.Product{}, int64(8), nil).Once()
+
+		req = httptest.NewRequest(http.MethodGet, "/catalog?sort=-price&limit=2&cursor="+response.NextCursor, nil)
+		rec = httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns no cursor for a full page requested by offset", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		products := []models.Product{
+			{ID: 7, Code: "PROD007", Price: decimal.NewFromFloat(18.2)},
+			{ID: 4, Code: "PROD004", Price: decimal.NewFromFloat(15)},
+		}
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Offset == 2 && filter.Limit == 2
+		})).Return(products, int64(8), nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?offset=2&limit=2", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response Response
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Products, 2)
+		assert.Empty(t, response.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for cursor created with another sort order", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		cursor := common.Cursor{Sort: "-price", Values: []string{"15"}, ID: 4}.Encode()
+		req := httptest.NewRequest(http.MethodGet, "/catalog?sort=code&cursor="+cursor, nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("returns 400 for cursor combined with offset", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		cursor := common.Cursor{ID: 4}.Encode()
+		req := httptest.NewRequest(http.MethodGet, "/catalog?offset=10&cursor="+cursor, nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("skips total when withTotal is false", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.SkipTotal
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?withTotal=false", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"total"`)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns requested facets for the active filters", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		maxPrice := decimal.NewFromInt(50)
+		facets := &repository.ProductFacets{
+			Categories: []repository.CategoryFacet{{Code: "SHOES", Name: "Shoes", Count: 3}},
+			Prices:     []repository.PriceFacet{{Min: decimal.Zero, Max: &maxPrice, Count: 2}},
+		}
+
+		isShoes := mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual([]string{"SHOES"}, filter.CategoryCodes)
+		})
+		mockRepo.On("GetProducts", isShoes).Return([]models.Product{}, int64(0), nil)
+		mockRepo.On("GetProductFacets", isShoes, []string{"category", "price"}).Return(facets, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?category=SHOES&facets=category,price", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response Response
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, int64(3), response.Facets.Categories[0].Count)
+		assert.Equal(t, int64(2), response.Facets.Prices[0].Count)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("leaves facets out unless requested", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.Anything).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"facets"`)
+		mockRepo.AssertNotCalled(t, "GetProductFacets", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 400 for unknown facet", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?facets=brand", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("supports pagination with offset and limit", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return filter.Offset == 5 && filter.Limit == 20
+		})).Return([]models.Product{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog?offset=5&limit=20", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleSearch(t *testing.T) {
+	t.Run("returns ranked and highlighted results", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		results := []repository.SearchResult{
+			{
+				Product: models.Product{
+					Code:     "PROD001",
+					Price:    decimal.NewFromFloat(10.99),
+					Variants: []models.Variant{{SKU: "SKU001A"}},
+				},
+				Rank:      0.6,
+				Highlight: "PROD001 Variant A <mark>SKU001A</mark>",
+			},
+		}
+
+		mockRepo.On("SearchProducts", "sku001a", mock.Anything).Return(results, int64(1), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/search?q=sku001a", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleSearch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response SearchResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Results, 1)
+		assert.Equal(t, int64(1), *response.Total)
+		assert.Equal(t, "PROD001 Variant A <mark>SKU001A</mark>", response.Results[0].Highlight)
+		assert.True(t, response.Results[0].Variants[0].PriceInherited)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("narrows results with catalog filters", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("SearchProducts", "variant", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual([]string{"SHOES"}, filter.CategoryCodes) &&
+				filter.MaxPrice != nil && filter.MaxPrice.Equal(decimal.NewFromFloat(20))
+		})).Return([]repository.SearchResult{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/search?q=variant&category=SHOES&priceLessThan=20", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleSearch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 without query", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/search?q=%20", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleSearch(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "SearchProducts", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleExport(t *testing.T) {
+	shirt := repository.ExportRow{
+		Code:       "PROD001",
+		Name:       "Shirt",
+		Price:      decimal.NewFromFloat(10.99),
+		Category:   "CLOTHING",
+		Categories: []string{"SALE", "SHIRTS"},
+		Status:     models.StatusPublished,
+	}
+	variants := []repository.ExportRow{shirt, shirt}
+	variants[0].SKU, variants[0].VariantName = "SKU001A", "M / Black"
+	variants[0].Options = []models.VariantOption{{Name: "Size", Value: "M"}, {Name: "Color", Value: "Black"}}
+	variants[1].SKU, variants[1].VariantName = "SKU001B", "L / Black"
+	variants[1].VariantPrice = decimal.NewNullDecimal(decimal.NewFromFloat(12.5))
+	variants[1].Options = []models.VariantOption{{Name: "Size", Value: "L"}, {Name: "Color", Value: "Black"}}
+
+	t.Run("streams csv with a column per option", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProductOptionNames", mock.Anything).Return([]string{"Size", "Color"}, nil)
+		mockRepo.On("ExportProducts", mock.Anything, mock.Anything).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=csv", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="catalog.csv"`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "code,name,description,brand,price,category,categories,status,sku,variantName,variantPrice,option:Size,option:Color\n"+
+			"PROD001,Shirt,,,10.99,CLOTHING,SALE|SHIRTS,published,SKU001A,M / Black,,M,Black\n"+
+			"PROD001,Shirt,,,10.99,CLOTHING,SALE|SHIRTS,published,SKU001B,L / Black,12.50,L,Black\n", rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("streams ndjson honoring the filters without pagination", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("ExportProducts", mock.MatchedBy(func(filter repository.ProductsFilter) bool {
+			return assert.ObjectsAreEqual([]string{"CLOTHING"}, filter.CategoryCodes) &&
+				filter.Scope == repository.AllProducts && filter.Limit == 0 && filter.Offset == 0
+		}), mock.Anything).Return(variants, nil)
+
+		req := common.WithAdmin(httptest.NewRequest(http.MethodGet, "/catalog/export?format=ndjson&category=CLOTHING&offset=20&limit=5", nil))
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+		decoder := json.NewDecoder(rec.Body)
+		var records []ExportRecord
+		for decoder.More() {
+			var record ExportRecord
+			assert.NoError(t, decoder.Decode(&record))
+			records = append(records, record)
+		}
+		assert.Len(t, records, 2)
+		assert.Equal(t, "SKU001A", records[0].SKU)
+		assert.Equal(t, []string{"SALE", "SHIRTS"}, records[0].Categories)
+		assert.Nil(t, records[0].VariantPrice)
+		assert.Equal(t, []ExportOption{{Name: "Size", Value: "M"}, {Name: "Color", Value: "Black"}}, records[0].Options)
+		assert.True(t, records[1].VariantPrice.Equal(decimal.NewFromFloat(12.5)))
+		mockRepo.AssertNotCalled(t, "GetProductOptionNames", mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("flushes while streaming", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		rows := make([]repository.ExportRow, exportFlushRows+1)
+		for i := range rows {
+			rows[i] = shirt
+		}
+		mockRepo.On("ExportProducts", mock.Anything, mock.Anything).Return(rows, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, exportFlushRows+1, strings.Count(rec.Body.String(), "\n"))
+	})
+
+	t.Run("exports only the csv header when nothing matches", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("GetProductOptionNames", mock.Anything).Return([]string{}, nil)
+		mockRepo.On("ExportProducts", mock.Anything, mock.Anything).Return(nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=csv", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "code,name,description,brand,price,category,categories,status,sku,variantName,variantPrice\n", rec.Body.String())
+	})
+
+	t.Run("rejects a currency other than the base currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=csv&currency=GBP", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "ExportProducts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an unknown format", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=xml", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "ExportProducts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 500 when the export fails before the first row", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("ExportProducts", mock.Anything, mock.Anything).Return(nil, errors.New("connection lost"))
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("cuts the stream short when the export fails midway", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewCatalogHandler(mockRepo)
+
+		mockRepo.On("ExportProducts", mock.Anything, mock.Anything).Return(variants[:1], errors.New("connection lost"))
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/export?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleExport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), "\n"))
+	})
 }
@@ -2,6 +2,7 @@ package categories
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -16,9 +17,30 @@ type CategoriesResponse struct {
 	Total      int64             `json:"total"`
 }
 
+type CategoryTreeResponse struct {
+	Categories []models.Category `json:"categories"`
+}
+
 type CreateCategoryRequest struct {
-	Code string `json:"code" validate:"required"`
-	Name string `json:"name" validate:"required"`
+	Code   string  `json:"code" validate:"required"`
+	Name   string  `json:"name" validate:"required"`
+	Parent *string `json:"parent"`
+}
+
+// UpdateCategoryRequest replaces the code, name and parent of a category.
+// Omitting the parent makes it a root category.
+type UpdateCategoryRequest struct {
+	Code   string  `json:"code" validate:"required"`
+	Name   string  `json:"name" validate:"required"`
+	Parent *string `json:"parent"`
+}
+
+// PatchCategoryRequest changes only the fields that are present.
+// An empty parent makes it a root category.
+type PatchCategoryRequest struct {
+	Code   *string `json:"code" validate:"omitempty,min=1"`
+	Name   *string `json:"name" validate:"omitempty,min=1"`
+	Parent *string `json:"parent"`
 }
 
 type CategoriesHandler struct {
@@ -33,7 +55,11 @@ func NewCategoriesHandler(r repository.CategoriesInterface) *CategoriesHandler {
 
 func (h *CategoriesHandler) HandleGetAll(w http.ResponseWriter, r *http.Request) {
 	// Process filters from request
-	filter := h.processFilters(r)
+	filter, err := h.processFilters(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Get categories from repository
 	categories, total, err := h.repo.GetAllCategories(filter)
@@ -50,6 +76,26 @@ func (h *CategoriesHandler) HandleGetAll(w http.ResponseWriter, r *http.Request)
 	api.OKResponse(w, response)
 }
 
+func (h *CategoriesHandler) HandleGetTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.repo.GetCategoryTree()
+	if err != nil {
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.OKResponse(w, CategoryTreeResponse{Categories: tree})
+}
+
+func (h *CategoriesHandler) HandleGetByCode(w http.ResponseWriter, r *http.Request) {
+	category, err := h.repo.GetCategoryByCode(r.PathValue("code"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, category)
+}
+
 func (h *CategoriesHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 	var req CreateCategoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -66,30 +112,170 @@ func (h *CategoriesHandler) HandleCreate(w http.ResponseWriter, r *http.Request)
 
 	// Create category
 	category := &models.Category{
-		Code: req.Code,
-		Name: req.Name,
+		Code:   req.Code,
+		Name:   req.Name,
+		Parent: parentRef(req.Parent),
 	}
 
 	if err := h.repo.CreateCategory(category); err != nil {
-		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		writeRepositoryError(w, err)
 		return
 	}
 
 	// Return created category
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(category)
+	api.CreatedResponse(w, category)
 }
 
-func (h *CategoriesHandler) processFilters(r *http.Request) repository.CategoriesFilter {
+func (h *CategoriesHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	var validate = validator.New()
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	category, err := h.repo.GetCategoryByCode(r.PathValue("code"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	category.Code = req.Code
+	category.Name = req.Name
+	category.Parent = parentRef(req.Parent)
+
+	if err := h.repo.UpdateCategory(category); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, category)
+}
+
+func (h *CategoriesHandler) HandlePatch(w http.ResponseWriter, r *http.Request) {
+	var req PatchCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	var validate = validator.New()
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	category, err := h.repo.GetCategoryByCode(r.PathValue("code"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	// Apply only the fields present in the request
+	if req.Code != nil {
+		category.Code = *req.Code
+	}
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.Parent != nil {
+		category.Parent = parentRef(req.Parent)
+	}
+
+	if err := h.repo.UpdateCategory(category); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, category)
+}
+
+// HandleDelete deletes a category. Products and subcategories still in the category are moved
+// to the category given by the reassignTo query parameter; without it the delete is refused.
+func (h *CategoriesHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	var reassignTo *string
+	if target := r.URL.Query().Get("reassignTo"); target != "" {
+		if target == code {
+			api.ErrorResponse(w, http.StatusBadRequest, "Validation error: cannot reassign products to the deleted category")
+			return
+		}
+		reassignTo = &target
+	}
+
+	if err := h.repo.DeleteCategory(code, reassignTo); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// HandleRestore restores a deleted category. Its parent must be restored first.
+func (h *CategoriesHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	category, err := h.repo.RestoreCategory(r.PathValue("code"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, category)
+}
+
+func (h *CategoriesHandler) processFilters(r *http.Request) (repository.CategoriesFilter, error) {
 	// Parse pagination parameters
 	offset, limit := common.ParseOffsetLimit(r)
 
-	// Build filter
+	// Parse sort order
+	sort, err := common.ParseSort(r, repository.CategorySortFields)
+	if err != nil {
+		return repository.CategoriesFilter{}, err
+	}
+
+	// Build filter; deleted categories are only listed when admin requests ask for them
 	filter := repository.CategoriesFilter{
-		Offset: offset,
-		Limit:  limit,
+		IncludeDeleted: common.IsAdmin(r) && r.URL.Query().Get("includeDeleted") == "true",
+		Sort:           sort,
+		Offset:         offset,
+		Limit:          limit,
 	}
 
-	return filter
+	return filter, nil
+}
+
+// parentRef builds the parent reference the repository resolves by code.
+// A missing or empty code means a root category.
+func parentRef(code *string) *models.Category {
+	if code == nil || *code == "" {
+		return nil
+	}
+	return &models.Category{Code: *code}
+}
+
+// writeRepositoryError maps repository errors to HTTP status codes.
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, "Category not found")
+	case errors.Is(err, repository.ErrConflict):
+		api.ErrorResponse(w, http.StatusConflict, "Category code already exists")
+	case errors.Is(err, repository.ErrInUse):
+		api.ErrorResponse(w, http.StatusConflict, "Category still has products or subcategories, pass reassignTo to move them")
+	case errors.Is(err, repository.ErrUnknownCategory):
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: unknown category")
+	case errors.Is(err, repository.ErrCategoryCycle):
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: category cannot be its own ancestor")
+	case errors.Is(err, repository.ErrParentDeleted):
+		api.ErrorResponse(w, http.StatusConflict, "Parent category is deleted, restore it first")
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
 }
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/repository"
 	"github.com/mytheresa/go-hiring-challenge/models"
 	"github.com/stretchr/testify/assert"
@@ -26,11 +27,45 @@ func (m *MockCategoriesRepository) GetAllCategories(filter repository.Categories
 	return args.Get(0).([]models.Category), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockCategoriesRepository) GetCategoryByCode(code string) (*models.Category, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockCategoriesRepository) GetCategoryTree() ([]models.Category, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
 func (m *MockCategoriesRepository) CreateCategory(category *models.Category) error {
 	args := m.Called(category)
 	return args.Error(0)
 }
 
+func (m *MockCategoriesRepository) UpdateCategory(category *models.Category) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockCategoriesRepository) DeleteCategory(code string, reassignTo *string) error {
+	args := m.Called(code, reassignTo)
+	return args.Error(0)
+}
+
+func (m *MockCategoriesRepository) RestoreCategory(code string) (*models.Category, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
 func TestHandleGetAll(t *testing.T) {
 	t.Run("returns all categories", func(t *testing.T) {
 		mockRepo := new(MockCategoriesRepository)
@@ -58,6 +93,70 @@ func TestHandleGetAll(t *testing.T) {
 
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("sorts by allowed fields", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetAllCategories", mock.MatchedBy(func(filter repository.CategoriesFilter) bool {
+			return assert.ObjectsAreEqual([]common.SortField{{Name: "name", Desc: true}}, filter.Sort)
+		})).Return([]models.Category{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/categories?sort=-name", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lists deleted categories when asked for", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetAllCategories", mock.MatchedBy(func(filter repository.CategoriesFilter) bool {
+			return filter.IncludeDeleted
+		})).Return([]models.Category{}, int64(0), nil)
+
+		req := common.WithAdmin(httptest.NewRequest(http.MethodGet, "/categories?includeDeleted=true", nil))
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ignores includeDeleted outside admin requests", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetAllCategories", mock.MatchedBy(func(filter repository.CategoriesFilter) bool {
+			return !filter.IncludeDeleted
+		})).Return([]models.Category{}, int64(0), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/categories?includeDeleted=true", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown sort field", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/categories?sort=price", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetAllCategories", mock.Anything)
+	})
 }
 
 func TestHandleCreate(t *testing.T) {
@@ -89,6 +188,40 @@ func TestHandleCreate(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("creates subcategory under parent", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("CreateCategory", mock.MatchedBy(func(cat *models.Category) bool {
+			return cat.Code == "DRESSES" && cat.Parent != nil && cat.Parent.Code == "CLOTHING"
+		})).Return(nil)
+
+		body := bytes.NewBufferString(`{"code":"DRESSES","name":"Dresses","parent":"CLOTHING"}`)
+		req := httptest.NewRequest(http.MethodPost, "/categories", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown parent", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("CreateCategory", mock.Anything).Return(repository.ErrUnknownCategory)
+
+		body := bytes.NewBufferString(`{"code":"DRESSES","name":"Dresses","parent":"NOTFOUND"}`)
+		req := httptest.NewRequest(http.MethodPost, "/categories", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("returns error for missing required fields", func(t *testing.T) {
 		mockRepo := new(MockCategoriesRepository)
 		handler := NewCategoriesHandler(mockRepo)
@@ -106,3 +239,309 @@ func TestHandleCreate(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	})
 }
+
+func TestHandleGetTree(t *testing.T) {
+	t.Run("returns nested categories", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		tree := []models.Category{
+			{
+				Code: "CLOTHING",
+				Name: "Clothing",
+				Children: []models.Category{
+					{
+						Code:     "DRESSES",
+						Name:     "Dresses",
+						Children: []models.Category{{Code: "MIDI_DRESSES", Name: "Midi Dresses"}},
+					},
+				},
+			},
+			{Code: "SHOES", Name: "Shoes"},
+		}
+
+		mockRepo.On("GetCategoryTree").Return(tree, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/categories/tree", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetTree(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response CategoryTreeResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Categories, 2)
+		assert.Equal(t, "MIDI_DRESSES", response.Categories[0].Children[0].Children[0].Code)
+		assert.Empty(t, response.Categories[1].Children)
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleGetByCode(t *testing.T) {
+	t.Run("returns category", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetCategoryByCode", "SHOES").Return(&models.Category{Code: "SHOES", Name: "Shoes"}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/categories/SHOES", nil)
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Category
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "Shoes", response.Name)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when category not found", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetCategoryByCode", "NOTFOUND").Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/categories/NOTFOUND", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleUpdate(t *testing.T) {
+	t.Run("replaces code and name", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetCategoryByCode", "SHOES").Return(&models.Category{ID: 2, Code: "SHOES", Name: "Shoes"}, nil)
+		mockRepo.On("UpdateCategory", mock.MatchedBy(func(cat *models.Category) bool {
+			return cat.ID == 2 && cat.Code == "FOOTWEAR" && cat.Name == "Footwear"
+		})).Return(nil)
+
+		body, _ := json.Marshal(UpdateCategoryRequest{Code: "FOOTWEAR", Name: "Footwear"})
+		req := httptest.NewRequest(http.MethodPut, "/categories/SHOES", bytes.NewBuffer(body))
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 when parent is a descendant", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetCategoryByCode", "CLOTHING").Return(&models.Category{ID: 1, Code: "CLOTHING", Name: "Clothing"}, nil)
+		mockRepo.On("UpdateCategory", mock.Anything).Return(repository.ErrCategoryCycle)
+
+		body := bytes.NewBufferString(`{"code":"CLOTHING","name":"Clothing","parent":"DRESSES"}`)
+		req := httptest.NewRequest(http.MethodPut, "/categories/CLOTHING", body)
+		req.SetPathValue("code", "CLOTHING")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 when code is taken", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetCategoryByCode", "SHOES").Return(&models.Category{ID: 2, Code: "SHOES", Name: "Shoes"}, nil)
+		mockRepo.On("UpdateCategory", mock.Anything).Return(repository.ErrConflict)
+
+		body, _ := json.Marshal(UpdateCategoryRequest{Code: "CLOTHING", Name: "Shoes"})
+		req := httptest.NewRequest(http.MethodPut, "/categories/SHOES", bytes.NewBuffer(body))
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandlePatch(t *testing.T) {
+	t.Run("keeps fields that are not present", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("GetCategoryByCode", "SHOES").Return(&models.Category{ID: 2, Code: "SHOES", Name: "Shoes"}, nil)
+		mockRepo.On("UpdateCategory", mock.MatchedBy(func(cat *models.Category) bool {
+			return cat.Code == "SHOES" && cat.Name == "Shoes & Boots"
+		})).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPatch, "/categories/SHOES", bytes.NewBufferString(`{"name":"Shoes & Boots"}`))
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("moves category to the root when parent is empty", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		parentID := uint(1)
+		category := &models.Category{
+			ID:       4,
+			Code:     "DRESSES",
+			Name:     "Dresses",
+			ParentID: &parentID,
+			Parent:   &models.Category{ID: 1, Code: "CLOTHING"},
+		}
+
+		mockRepo.On("GetCategoryByCode", "DRESSES").Return(category, nil)
+		mockRepo.On("UpdateCategory", mock.MatchedBy(func(cat *models.Category) bool {
+			return cat.Name == "Dresses" && cat.Parent == nil
+		})).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPatch, "/categories/DRESSES", bytes.NewBufferString(`{"parent":""}`))
+		req.SetPathValue("code", "DRESSES")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleDelete(t *testing.T) {
+	t.Run("deletes category without products", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("DeleteCategory", "SHOES", (*string)(nil)).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/SHOES", nil)
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("refuses while products reference the category", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("DeleteCategory", "SHOES", (*string)(nil)).Return(repository.ErrInUse)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/SHOES", nil)
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reassigns products to the target category", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("DeleteCategory", "SHOES", mock.MatchedBy(func(target *string) bool {
+			return target != nil && *target == "ACCESSORIES"
+		})).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/SHOES?reassignTo=ACCESSORIES", nil)
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error when reassigning to itself", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/SHOES?reassignTo=SHOES", nil)
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "DeleteCategory", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleRestore(t *testing.T) {
+	t.Run("restores a deleted category", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("RestoreCategory", "SHOES").Return(&models.Category{Code: "SHOES", Name: "Shoes"}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/categories/SHOES/restore", nil)
+		req.SetPathValue("code", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Category
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "SHOES", response.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 while the parent is deleted", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("RestoreCategory", "SNEAKERS").Return(nil, repository.ErrParentDeleted)
+
+		req := httptest.NewRequest(http.MethodPost, "/categories/SNEAKERS/restore", nil)
+		req.SetPathValue("code", "SNEAKERS")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when no deleted category has the code", func(t *testing.T) {
+		mockRepo := new(MockCategoriesRepository)
+		handler := NewCategoriesHandler(mockRepo)
+
+		mockRepo.On("RestoreCategory", "NOTFOUND").Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodPost, "/categories/NOTFOUND/restore", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,90 @@
+package imports
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
+	"github.com/mytheresa/go-hiring-challenge/internal/importer"
+)
+
+// maxImportSize caps the size of an uploaded import file.
+const maxImportSize = 32 << 20
+
+// contentTypeFormats maps the content types of import files to their format.
+var contentTypeFormats = map[string]string{
+	"text/csv":             importer.FormatCSV,
+	"application/x-ndjson": importer.FormatNDJSON,
+	"application/ndjson":   importer.FormatNDJSON,
+}
+
+type ImportHandler struct {
+	importer importer.ImporterInterface
+}
+
+func NewImportHandler(i importer.ImporterInterface) *ImportHandler {
+	return &ImportHandler{
+		importer: i,
+	}
+}
+
+// HandleImport imports products and variants from a CSV or NDJSON body. The format is taken from
+// the format parameter or the Content-Type. dryRun=true only reports what would change and
+// batchSize=N commits every N products separately. The report lists every row; it is returned
+// with 422 when rows are invalid or failed.
+func (h *ImportHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	format, err := parseFormat(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	options := importer.Options{
+		DryRun: r.URL.Query().Get("dryRun") == "true",
+		Actor:  common.ParseActor(r),
+	}
+	if param := r.URL.Query().Get("batchSize"); param != "" {
+		if options.BatchSize, err = strconv.Atoi(param); err != nil || options.BatchSize < 1 {
+			api.ErrorResponse(w, http.StatusBadRequest, "batchSize must be a positive number")
+			return
+		}
+	}
+
+	rows, err := importer.Parse(http.MaxBytesReader(w, r.Body, maxImportSize), format)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid import file: "+err.Error())
+		return
+	}
+
+	report, err := h.importer.Import(rows, options)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !report.Succeeded() {
+		api.UnprocessableEntityResponse(w, report)
+		return
+	}
+	api.OKResponse(w, report)
+}
+
+// parseFormat reads the format parameter, falling back to the Content-Type of the body.
+func parseFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if !slices.Contains(importer.Formats, format) {
+			return "", errors.New("format must be csv or ndjson")
+		}
+		return format, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if format, ok := contentTypeFormats[mediaType]; ok {
+		return format, nil
+	}
+	return "", errors.New("format must be csv or ndjson, set by the format parameter or the Content-Type")
+}
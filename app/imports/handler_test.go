@@ -0,0 +1,207 @@
+package imports
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/importer"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockImporter is a mock implementation of ImporterInterface
+type MockImporter struct {
+	mock.Mock
+}
+
+func (m *MockImporter) Import(rows []importer.Row, options importer.Options) (*importer.Report, error) {
+	args := m.Called(rows, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*importer.Report), args.Error(1)
+}
+
+const csvFile = `code,name,price,category,sku,variantPrice,option:size
+PROD001,Shirt,10.99,CLOTHING,SKU001A,,M
+PROD001,,,,SKU001B,12.50,L
+`
+
+func TestHandleImport(t *testing.T) {
+	t.Run("imports csv rows in one transaction", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		report := &importer.Report{
+			Rows: []importer.RowReport{
+				{Line: 2, Code: "PROD001", SKU: "SKU001A", Status: importer.StatusCreated},
+				{Line: 3, Code: "PROD001", SKU: "SKU001B", Status: importer.StatusCreated},
+			},
+			Created: 2,
+		}
+		mockImporter.On("Import", mock.MatchedBy(func(rows []importer.Row) bool {
+			return len(rows) == 2 &&
+				rows[0].Line == 2 && rows[0].Code == "PROD001" && rows[0].Price.Equal(decimal.RequireFromString("10.99")) &&
+				rows[0].VariantPrice == nil &&
+				assert.ObjectsAreEqual([]importer.Option{{Name: "size", Value: "M"}}, rows[0].Options) &&
+				rows[1].Price == nil && rows[1].VariantPrice.Equal(decimal.RequireFromString("12.50"))
+		}), importer.Options{Actor: "buyer"}).Return(report, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import", strings.NewReader(csvFile))
+		req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+		req.Header.Set("X-Actor", "buyer")
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response importer.Report
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, 2, response.Created)
+		assert.Len(t, response.Rows, 2)
+		mockImporter.AssertExpectations(t)
+	})
+
+	t.Run("dry runs ndjson rows in batches", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		mockImporter.On("Import", mock.MatchedBy(func(rows []importer.Row) bool {
+			return len(rows) == 1 && rows[0].Line == 1 && rows[0].SKU == "SKU001A" &&
+				rows[0].Price.Equal(decimal.RequireFromString("10.99"))
+		}), importer.Options{DryRun: true, BatchSize: 50, Actor: "anonymous"}).
+			Return(&importer.Report{DryRun: true, Updated: 1}, nil)
+
+		body := `{"code":"PROD001","price":10.99,"sku":"SKU001A","options":[{"name":"size","value":"M"}]}` + "\n\n"
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=ndjson&dryRun=true&batchSize=50", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockImporter.AssertExpectations(t)
+	})
+
+	t.Run("reports invalid rows with 422", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		report := &importer.Report{
+			Rows: []importer.RowReport{
+				{Line: 2, Code: "PROD001", Status: importer.StatusInvalid, Errors: []string{"price: \"abc\" is not a decimal"}},
+			},
+			Invalid: 1,
+		}
+		mockImporter.On("Import", mock.MatchedBy(func(rows []importer.Row) bool {
+			return len(rows) == 1 && rows[0].Price == nil && len(rows[0].Errors) == 1
+		}), mock.Anything).Return(report, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=csv", strings.NewReader("code,price\nPROD001,abc\n"))
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		var response importer.Report
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, 1, response.Invalid)
+		assert.Equal(t, importer.StatusInvalid, response.Rows[0].Status)
+		mockImporter.AssertExpectations(t)
+	})
+
+	t.Run("keeps ndjson lines that are not rows in the report", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		mockImporter.On("Import", mock.MatchedBy(func(rows []importer.Row) bool {
+			return len(rows) == 2 && len(rows[0].Errors) == 0 && rows[1].Line == 2 && len(rows[1].Errors) == 1
+		}), mock.Anything).Return(&importer.Report{Invalid: 1, Skipped: 1}, nil)
+
+		body := `{"code":"PROD001","price":"10.99"}` + "\n" + `{"code":"PROD002","colour":"red"}`
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		mockImporter.AssertExpectations(t)
+	})
+
+	t.Run("rejects an unknown format", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=xlsx", strings.NewReader(csvFile))
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockImporter.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a format", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import", strings.NewReader(csvFile))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockImporter.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an invalid batch size", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=csv&batchSize=0", strings.NewReader(csvFile))
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockImporter.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects csv with unknown or missing columns", func(t *testing.T) {
+		for _, body := range []string{"code,price,colour\nPROD001,1,red\n", "name,price\nShirt,1\n", ""} {
+			mockImporter := new(MockImporter)
+			handler := NewImportHandler(mockImporter)
+
+			req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=csv", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			handler.HandleImport(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+			mockImporter.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("returns 500 when the import fails", func(t *testing.T) {
+		mockImporter := new(MockImporter)
+		handler := NewImportHandler(mockImporter)
+
+		mockImporter.On("Import", mock.Anything, mock.Anything).Return(nil, errors.New("connection lost"))
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=csv", strings.NewReader(csvFile))
+		rec := httptest.NewRecorder()
+
+		handler.HandleImport(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockImporter.AssertExpectations(t)
+	})
+}
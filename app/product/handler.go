@@ -1,12 +1,83 @@
 package product
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
 )
 
+type CreateProductRequest struct {
+	Code        string           `json:"code" validate:"required,max=32"`
+	Name        string           `json:"name" validate:"max=256"`
+	Description string           `json:"description"`
+	Brand       string           `json:"brand" validate:"max=128"`
+	Price       *decimal.Decimal `json:"price" validate:"required"`
+	Category    *string          `json:"category"`
+	Images      []ImageRequest   `json:"images" validate:"dive"`
+	Options     []string         `json:"options" validate:"unique,dive,required,max=64"`
+	Status      string           `json:"status" validate:"omitempty,oneof=draft published"`
+	PublishAt   *time.Time       `json:"publishAt"`
+	UnpublishAt *time.Time       `json:"unpublishAt"`
+}
+
+// UpdateProductRequest replaces every editable field of a product.
+// Omitting the category removes the product from its category; omitting images or options removes them.
+type UpdateProductRequest struct {
+	Name        string           `json:"name" validate:"max=256"`
+	Description string           `json:"description"`
+	Brand       string           `json:"brand" validate:"max=128"`
+	Price       *decimal.Decimal `json:"price" validate:"required"`
+	Category    *string          `json:"category"`
+	Images      []ImageRequest   `json:"images" validate:"dive"`
+	Options     []string         `json:"options" validate:"unique,dive,required,max=64"`
+}
+
+// PatchProductRequest changes only the fields that are present.
+// An empty category removes the product from its category; images and options replace the whole list.
+type PatchProductRequest struct {
+	Name        *string          `json:"name" validate:"omitnil,max=256"`
+	Description *string          `json:"description"`
+	Brand       *string          `json:"brand" validate:"omitnil,max=128"`
+	Price       *decimal.Decimal `json:"price"`
+	Category    *string          `json:"category"`
+	Images      *[]ImageRequest  `json:"images" validate:"omitnil,dive"`
+	Options     *[]string        `json:"options" validate:"omitnil,unique,dive,required,max=64"`
+}
+
+// ImageRequest is a product image; images are shown in the order they are listed.
+type ImageRequest struct {
+	URL     string `json:"url" validate:"required,url,max=1024"`
+	AltText string `json:"altText" validate:"max=256"`
+}
+
+// ChangeStatusRequest moves a product through the publication workflow. PublishAt and
+// UnpublishAt schedule when a published product goes live and when it leaves the storefront.
+type ChangeStatusRequest struct {
+	Status      string     `json:"status" validate:"required,oneof=draft published archived"`
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
+}
+
+// SetPriceRequest sets the price of a product or variant in a currency other than the base currency.
+type SetPriceRequest struct {
+	Amount *decimal.Decimal `json:"amount" validate:"required"`
+}
+
+// AttachCategoriesRequest lists the codes of the categories a product is added to.
+type AttachCategoriesRequest struct {
+	Categories []string `json:"categories" validate:"required,min=1,dive,required"`
+}
+
+var validate = validator.New()
+
 type ProductHandler struct {
 	repo repository.ProductsInterface
 }
@@ -24,13 +95,402 @@ func (h *ProductHandler) HandleGetByCode(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	currency, err := common.ParseCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Unpublished products are only shown to admin requests, deleted ones only when they ask for them
+	scope := repository.PublishedProducts
+	if common.IsAdmin(r) {
+		scope = repository.AllProducts
+		if r.URL.Query().Get("includeDeleted") == "true" {
+			scope = repository.AllProductsWithDeleted
+		}
+	}
+
 	// Get product from repository
-	product, err := h.repo.GetProductByCode(code)
+	product, err := h.repo.GetProductByCode(code, scope)
 	if err != nil {
 		api.ErrorResponse(w, http.StatusNotFound, "Product not found")
 		return
 	}
 
-	// Return the product as a JSON response
+	// Return the product with resolved variant prices in the requested currency as a JSON response
+	if err := product.ApplyCurrency(currency); err != nil {
+		api.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	product.ApplyVariantPrices()
+	api.OKResponse(w, product)
+}
+
+func (h *ProductHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Price.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: price must not be negative")
+		return
+	}
+	if !validWindow(req.PublishAt, req.UnpublishAt) {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: unpublishAt must be after publishAt")
+		return
+	}
+	if req.Status == "" {
+		req.Status = models.StatusDraft
+	}
+
+	// Create product
+	product := &models.Product{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Brand:       req.Brand,
+		Price:       *req.Price,
+		Category:    categoryRef(req.Category),
+		Images:      images(req.Images),
+		Options:     options(req.Options),
+		Status:      req.Status,
+		PublishAt:   req.PublishAt,
+		UnpublishAt: req.UnpublishAt,
+	}
+
+	if err := h.repo.CreateProduct(product, common.ParseActor(r)); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	// Return created product
+	withResolvedPrices(product)
+	api.CreatedResponse(w, product)
+}
+
+func (h *ProductHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Price.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: price must not be negative")
+		return
+	}
+
+	product, err := h.repo.GetProductByCode(r.PathValue("code"), repository.AllProducts)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	// Replace editable fields
+	product.Name = req.Name
+	product.Description = req.Description
+	product.Brand = req.Brand
+	product.Price = *req.Price
+	product.Category = categoryRef(req.Category)
+	product.Images = images(req.Images)
+	product.Options = options(req.Options)
+
+	if err := h.repo.UpdateProduct(product, common.ParseActor(r)); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withResolvedPrices(product)
 	api.OKResponse(w, product)
 }
+
+func (h *ProductHandler) HandlePatch(w http.ResponseWriter, r *http.Request) {
+	var req PatchProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Price != nil && req.Price.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: price must not be negative")
+		return
+	}
+
+	product, err := h.repo.GetProductByCode(r.PathValue("code"), repository.AllProducts)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	// Apply only the fields present in the request
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.Brand != nil {
+		product.Brand = *req.Brand
+	}
+	if req.Images != nil {
+		product.Images = images(*req.Images)
+	}
+	if req.Options != nil {
+		product.Options = options(*req.Options)
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.Category != nil {
+		product.Category = categoryRef(req.Category)
+	}
+
+	if err := h.repo.UpdateProduct(product, common.ParseActor(r)); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withResolvedPrices(product)
+	api.OKResponse(w, product)
+}
+
+// HandleChangeStatus publishes, unpublishes or archives a product, optionally scheduling
+// its publication window. Transitions the workflow does not allow are refused with 409.
+func (h *ProductHandler) HandleChangeStatus(w http.ResponseWriter, r *http.Request) {
+	var req ChangeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if !validWindow(req.PublishAt, req.UnpublishAt) {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: unpublishAt must be after publishAt")
+		return
+	}
+
+	product, err := h.repo.ChangeProductStatus(r.PathValue("code"), req.Status, req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withResolvedPrices(product)
+	api.OKResponse(w, product)
+}
+
+func (h *ProductHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteProduct(r.PathValue("code")); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// HandleRestore restores a deleted product along with the variants deleted with it. It is
+// refused with 409 if the product code or one of the SKUs has been reused.
+func (h *ProductHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	product, err := h.repo.RestoreProduct(r.PathValue("code"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withResolvedPrices(product)
+	api.OKResponse(w, product)
+}
+
+// HandlePriceHistory lists the price changes of a product and its variants, newest first.
+// Unpublished products are only found by admin requests.
+func (h *ProductHandler) HandlePriceHistory(w http.ResponseWriter, r *http.Request) {
+	scope := repository.PublishedProducts
+	if common.IsAdmin(r) {
+		scope = repository.AllProducts
+	}
+
+	changes, err := h.repo.GetPriceHistory(r.PathValue("code"), scope)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, changes)
+}
+
+// HandleSetPrice sets the price of a product in the currency from the path, adding the currency
+// to the price list or replacing its price. EUR prices are changed with the product price.
+func (h *ProductHandler) HandleSetPrice(w http.ResponseWriter, r *http.Request) {
+	currency, err := common.ParsePriceListCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req SetPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Amount.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: amount must not be negative")
+		return
+	}
+
+	price, err := h.repo.SetProductPrice(r.PathValue("code"), currency, *req.Amount, common.ParseActor(r))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, price)
+}
+
+// HandleDeletePrice removes the currency from the price list of a product, which is then no
+// longer sold in it.
+func (h *ProductHandler) HandleDeletePrice(w http.ResponseWriter, r *http.Request) {
+	currency, err := common.ParsePriceListCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.repo.DeleteProductPrice(r.PathValue("code"), currency, common.ParseActor(r)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.ErrorResponse(w, http.StatusNotFound, "Product not found or not priced in "+currency)
+			return
+		}
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// HandleAttachCategories assigns the product to additional categories and returns the updated product.
+func (h *ProductHandler) HandleAttachCategories(w http.ResponseWriter, r *http.Request) {
+	var req AttachCategoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	code := r.PathValue("code")
+	if err := h.repo.AttachCategories(code, req.Categories); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	product, err := h.repo.GetProductByCode(code, repository.AllProducts)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withResolvedPrices(product)
+	api.OKResponse(w, product)
+}
+
+func (h *ProductHandler) HandleDetachCategory(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DetachCategory(r.PathValue("code"), r.PathValue("category")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.ErrorResponse(w, http.StatusNotFound, "Product is not assigned to this category")
+			return
+		}
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// withResolvedPrices prices a product in a write response like HandleGetByCode prices it for
+// a request without currency: in the base currency, with its active sales applied.
+func withResolvedPrices(product *models.Product) {
+	// Every product and variant has a price in the base currency
+	_ = product.ApplyCurrency(models.BaseCurrency)
+	product.ApplyVariantPrices()
+}
+
+// categoryRef builds the category reference the repository resolves by code.
+// A missing or empty code means the product has no category.
+func categoryRef(code *string) *models.Category {
+	if code == nil || *code == "" {
+		return nil
+	}
+	return &models.Category{Code: *code}
+}
+
+// images converts requested images to models, keeping their order.
+func images(reqs []ImageRequest) []models.ProductImage {
+	result := make([]models.ProductImage, len(reqs))
+	for i, req := range reqs {
+		result[i] = models.ProductImage{URL: req.URL, AltText: req.AltText}
+	}
+	return result
+}
+
+// options converts requested option names to models, keeping their order.
+func options(names []string) []models.ProductOption {
+	result := make([]models.ProductOption, len(names))
+	for i, name := range names {
+		result[i] = models.ProductOption{Name: name}
+	}
+	return result
+}
+
+// validWindow reports whether a publication window ends after it starts; open ends are always valid.
+func validWindow(publishAt, unpublishAt *time.Time) bool {
+	return publishAt == nil || unpublishAt == nil || unpublishAt.After(*publishAt)
+}
+
+// writeRepositoryError maps repository errors to HTTP status codes.
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, "Product not found")
+	case errors.Is(err, repository.ErrConflict):
+		api.ErrorResponse(w, http.StatusConflict, "Product code already exists")
+	case errors.Is(err, repository.ErrUnknownCategory):
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: unknown category")
+	case errors.Is(err, repository.ErrOptionsInUse):
+		api.ErrorResponse(w, http.StatusConflict, "Product options cannot change while the product has variants")
+	case errors.Is(err, repository.ErrInvalidTransition):
+		api.ErrorResponse(w, http.StatusConflict, "Product cannot move to this status")
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,17 +1,21 @@
 package product
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/repository"
 	"github.com/mytheresa/go-hiring-challenge/models"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // MockProductsRepository is a mock implementation of ProductsInterface
@@ -27,7 +31,95 @@ func (m *MockProductsRepository) GetProducts(filter repository.ProductsFilter) (
 	return args.Get(0).([]models.Product), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockProductsRepository) GetProductByCode(code string) (*models.Product, error) {
+func (m *MockProductsRepository) GetProductByCode(code string, scope repository.ProductScope) (*models.Product, error) {
+	args := m.Called(code, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductsRepository) SearchProducts(terms string, filter repository.ProductsFilter) ([]repository.SearchResult, int64, error) {
+	args := m.Called(terms, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]repository.SearchResult), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProductsRepository) GetProductFacets(filter repository.ProductsFilter, facets []string) (*repository.ProductFacets, error) {
+	args := m.Called(filter, facets)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ProductFacets), args.Error(1)
+}
+
+func (m *MockProductsRepository) GetProductOptionNames(filter repository.ProductsFilter) ([]string, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockProductsRepository) ExportProducts(filter repository.ProductsFilter, fn func(repository.ExportRow) error) error {
+	args := m.Called(filter, fn)
+	if rows, ok := args.Get(0).([]repository.ExportRow); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductsRepository) CreateProduct(product *models.Product, actor string) error {
+	args := m.Called(product, actor)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) UpdateProduct(product *models.Product, actor string) error {
+	args := m.Called(product, actor)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) ChangeProductStatus(code, status string, publishAt, unpublishAt *time.Time) (*models.Product, error) {
+	args := m.Called(code, status, publishAt, unpublishAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductsRepository) GetPriceHistory(code string, scope repository.ProductScope) ([]models.PriceChange, error) {
+	args := m.Called(code, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceChange), args.Error(1)
+}
+
+func (m *MockProductsRepository) SetProductPrice(code, currency string, amount decimal.Decimal, actor string) (*models.ProductPrice, error) {
+	args := m.Called(code, currency, amount, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductPrice), args.Error(1)
+}
+
+func (m *MockProductsRepository) DeleteProductPrice(code, currency, actor string) error {
+	args := m.Called(code, currency, actor)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) DeleteProduct(code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) RestoreProduct(code string) (*models.Product, error) {
 	args := m.Called(code)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,6 +127,16 @@ func (m *MockProductsRepository) GetProductByCode(code string) (*models.Product,
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
+func (m *MockProductsRepository) AttachCategories(code string, categoryCodes []string) error {
+	args := m.Called(code, categoryCodes)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) DetachCategory(code, categoryCode string) error {
+	args := m.Called(code, categoryCode)
+	return args.Error(0)
+}
+
 func TestHandleGetByCode(t *testing.T) {
 	t.Run("returns product with category and variants", func(t *testing.T) {
 		mockRepo := new(MockProductsRepository)
@@ -54,17 +156,21 @@ func TestHandleGetByCode(t *testing.T) {
 				{
 					Name:  "Variant A",
 					SKU:   "SKU001A",
-					Price: decimal.NewFromFloat(11.99),
+					Price: decimal.NewNullDecimal(decimal.NewFromFloat(11.99)),
+				},
+				{
+					Name: "Variant B",
+					SKU:  "SKU001B",
 				},
 				{
-					Name:  "Variant B",
-					SKU:   "SKU001B",
-					Price: decimal.Zero,
+					Name:  "Variant C",
+					SKU:   "SKU001C",
+					Price: decimal.NewNullDecimal(decimal.Zero),
 				},
 			},
 		}
 
-		mockRepo.On("GetProductByCode", "PROD001").Return(product, nil)
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001", nil)
 		req.SetPathValue("code", "PROD001")
@@ -79,8 +185,194 @@ func TestHandleGetByCode(t *testing.T) {
 		assert.Equal(t, "PROD001", response.Code)
 		assert.NotNil(t, response.Category)
 		assert.Equal(t, "CLOTHING", response.Category.Code)
-		assert.Len(t, response.Variants, 2)
+		assert.Len(t, response.Variants, 3)
+
+		// Own price is kept
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(11.99)))
+		assert.False(t, response.Variants[0].PriceInherited)
+		// Missing price is inherited from the product
+		assert.True(t, response.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(10.99)))
+		assert.True(t, response.Variants[1].PriceInherited)
+		// Zero is a real price, not a missing one
+		assert.True(t, response.Variants[2].EffectivePrice.IsZero())
+		assert.False(t, response.Variants[2].PriceInherited)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns prices in the requested currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			Code:   "PROD001",
+			Price:  decimal.NewFromFloat(10.99),
+			Prices: []models.ProductPrice{{Currency: "GBP", Amount: decimal.NewFromFloat(9.49)}},
+			Variants: []models.Variant{
+				{
+					SKU:    "SKU001A",
+					Price:  decimal.NewNullDecimal(decimal.NewFromFloat(11.99)),
+					Prices: []models.VariantPrice{{Currency: "GBP", Amount: decimal.NewFromFloat(10.29)}},
+				},
+				{SKU: "SKU001B"},
+			},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001?currency=GBP", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "GBP", response.Currency)
+		assert.True(t, response.Price.Equal(decimal.NewFromFloat(9.49)))
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(10.29)))
+		assert.True(t, response.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(9.49)))
+		assert.True(t, response.Variants[1].PriceInherited)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns sale prices of variants", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			Code:  "PROD001",
+			Price: decimal.NewFromFloat(10),
+			Variants: []models.Variant{
+				{
+					SKU:   "SKU001A",
+					Price: decimal.NewNullDecimal(decimal.NewFromFloat(12)),
+					Sales: []models.SalePrice{
+						{Currency: "GBP", Price: decimal.NewFromFloat(5)},
+						{Currency: "EUR", Price: decimal.NewFromFloat(9)},
+					},
+				},
+				{SKU: "SKU001B", Sales: []models.SalePrice{{Currency: "EUR", Price: decimal.NewFromFloat(8)}}},
+			},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Nil(t, response.OriginalPrice)
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(9)))
+		assert.True(t, response.Variants[0].OriginalPrice.Equal(decimal.NewFromFloat(12)))
+		assert.Equal(t, 25, response.Variants[0].DiscountPercent)
+		// A sale on an inheriting variant is marked down from the product price
+		assert.True(t, response.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(8)))
+		assert.True(t, response.Variants[1].OriginalPrice.Equal(decimal.NewFromFloat(10)))
+		assert.Equal(t, 20, response.Variants[1].DiscountPercent)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 422 when product has no price in the currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{Code: "PROD006", Price: decimal.NewFromFloat(7.99)}
+		mockRepo.On("GetProductByCode", "PROD006", mock.Anything).Return(product, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD006", nil)
+		req.Header.Set("Accept-Currency", "USD")
+		req.SetPathValue("code", "PROD006")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("shows unpublished products to admin requests only", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{Code: "PROD007", Price: decimal.NewFromFloat(18.2), Status: models.StatusDraft}
+		mockRepo.On("GetProductByCode", "PROD007", repository.PublishedProducts).Return(nil, repository.ErrNotFound)
+		mockRepo.On("GetProductByCode", "PROD007", repository.AllProducts).Return(product, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD007", nil)
+		req.SetPathValue("code", "PROD007")
+		rec := httptest.NewRecorder()
 
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		req = common.WithAdmin(httptest.NewRequest(http.MethodGet, "/catalog/PROD007", nil))
+		req.SetPathValue("code", "PROD007")
+		rec = httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, models.StatusDraft, response.Status)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("shows deleted products when asked for", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		deletedAt := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+		product := &models.Product{
+			Code:      "PROD001",
+			Price:     decimal.NewFromFloat(10.99),
+			DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true},
+		}
+		mockRepo.On("GetProductByCode", "PROD001", repository.AllProductsWithDeleted).Return(product, nil)
+
+		req := common.WithAdmin(httptest.NewRequest(http.MethodGet, "/catalog/PROD001?includeDeleted=true", nil))
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.True(t, response.DeletedAt.Valid)
+		assert.True(t, response.DeletedAt.Time.Equal(deletedAt))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ignores admin and includeDeleted parameters outside admin requests", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("GetProductByCode", "PROD001", repository.PublishedProducts).Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001?admin=true&includeDeleted=true", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetByCode(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -88,7 +380,7 @@ func TestHandleGetByCode(t *testing.T) {
 		mockRepo := new(MockProductsRepository)
 		handler := NewProductHandler(mockRepo)
 
-		mockRepo.On("GetProductByCode", "NOTFOUND").Return(nil, errors.New("not found"))
+		mockRepo.On("GetProductByCode", "NOTFOUND", mock.Anything).Return(nil, errors.New("not found"))
 
 		req := httptest.NewRequest(http.MethodGet, "/catalog/NOTFOUND", nil)
 		req.SetPathValue("code", "NOTFOUND")
@@ -101,3 +393,912 @@ func TestHandleGetByCode(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestHandleCreate(t *testing.T) {
+	t.Run("creates new product in category", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("CreateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Code == "PROD009" &&
+				p.Price.Equal(decimal.NewFromFloat(19.99)) &&
+				p.Category != nil && p.Category.Code == "SHOES"
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","category":"SHOES"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "PROD009", response.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("creates drafts unless asked to publish", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("CreateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Code == "PROD009" && p.Status == models.StatusDraft
+		}), mock.Anything).Return(nil)
+		mockRepo.On("CreateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Code == "PROD010" && p.Status == models.StatusPublished && p.PublishAt != nil
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		body = bytes.NewBufferString(`{"code":"PROD010","price":"19.99","status":"published","publishAt":"2026-12-01T08:00:00Z"}`)
+		req = httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec = httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for archived new product", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","status":"archived"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("creates product with attributes and ordered images", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("CreateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Name == "Suede Boots" && p.Brand == "Gianvito Rossi" && p.Description == "Ankle boots" &&
+				len(p.Images) == 2 &&
+				p.Images[0].URL == "https://img.example.com/front.jpg" && p.Images[0].AltText == "Front" &&
+				p.Images[1].URL == "https://img.example.com/side.jpg"
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","name":"Suede Boots","brand":"Gianvito Rossi",` +
+			`"description":"Ankle boots","images":[{"url":"https://img.example.com/front.jpg","altText":"Front"},` +
+			`{"url":"https://img.example.com/side.jpg"}]}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "Suede Boots", response.Name)
+		assert.Len(t, response.Images, 2)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("creates product with ordered options", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("CreateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return len(p.Options) == 2 && p.Options[0].Name == "Size" && p.Options[1].Name == "Color"
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","options":["Size","Color"]}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for duplicate options", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","options":["Size","Size"]}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error for invalid image url", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","images":[{"url":"not a url"}]}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error for missing required fields", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"code":"PROD009"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error for negative price", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"-1"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 400 for unknown category", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(repository.ErrUnknownCategory)
+
+		body := bytes.NewBufferString(`{"code":"PROD009","price":"19.99","category":"TOYS"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 for duplicate code", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(repository.ErrConflict)
+
+		body := bytes.NewBufferString(`{"code":"PROD001","price":"19.99"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleUpdate(t *testing.T) {
+	t.Run("replaces price and category", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			ID:       1,
+			Code:     "PROD001",
+			Price:    decimal.NewFromFloat(10.99),
+			Category: &models.Category{Code: "CLOTHING"},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", repository.AllProducts).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Price.Equal(decimal.NewFromFloat(12.5)) && p.Category == nil
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"price":"12.50"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the sale price while a sale is active", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			ID:       1,
+			Code:     "PROD001",
+			Price:    decimal.NewFromFloat(10.99),
+			Sales:    []models.SalePrice{{Currency: "EUR", Price: decimal.NewFromFloat(9)}},
+			Variants: []models.Variant{{SKU: "SKU001A"}},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", repository.AllProducts).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.Anything, mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"price":"12.00"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.True(t, response.Price.Equal(decimal.NewFromFloat(9)))
+		assert.True(t, response.OriginalPrice.Equal(decimal.NewFromFloat(12)))
+		assert.Equal(t, 25, response.DiscountPercent)
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(9)))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("records the actor of the change", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.Anything, "jane.doe").Return(nil)
+
+		body := bytes.NewBufferString(`{"price":"9.99"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001", body)
+		req.Header.Set("X-Actor", "jane.doe")
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when product not found", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("GetProductByCode", "NOTFOUND", mock.Anything).Return(nil, repository.ErrNotFound)
+
+		body := bytes.NewBufferString(`{"price":"12.50"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/NOTFOUND", body)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for missing price", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"category":"SHOES"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProductByCode", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandlePatch(t *testing.T) {
+	t.Run("keeps fields that are not present", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			ID:       1,
+			Code:     "PROD001",
+			Price:    decimal.NewFromFloat(10.99),
+			Category: &models.Category{Code: "CLOTHING"},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Price.Equal(decimal.NewFromFloat(9.99)) &&
+				p.Category != nil && p.Category.Code == "CLOTHING"
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"price":"9.99"}`)
+		req := httptest.NewRequest(http.MethodPatch, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("updates attributes and keeps images that are not present", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			ID:     1,
+			Code:   "PROD001",
+			Name:   "T-Shirt",
+			Price:  decimal.NewFromFloat(10.99),
+			Images: []models.ProductImage{{URL: "https://img.example.com/front.jpg"}},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Name == "Crew Neck T-Shirt" && len(p.Images) == 1
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"name":"Crew Neck T-Shirt"}`)
+		req := httptest.NewRequest(http.MethodPatch, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 when options change on a product with variants", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.Anything, mock.Anything).Return(repository.ErrOptionsInUse)
+
+		body := bytes.NewBufferString(`{"options":["Size"]}`)
+		req := httptest.NewRequest(http.MethodPatch, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for invalid patched image", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"images":[{"altText":"Front"}]}`)
+		req := httptest.NewRequest(http.MethodPatch, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetProductByCode", mock.Anything, mock.Anything)
+	})
+
+	t.Run("removes category when empty", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			ID:       1,
+			Code:     "PROD001",
+			Price:    decimal.NewFromFloat(10.99),
+			Category: &models.Category{Code: "CLOTHING"},
+		}
+
+		mockRepo.On("GetProductByCode", "PROD001", mock.Anything).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Price.Equal(decimal.NewFromFloat(10.99)) && p.Category == nil
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"category":""}`)
+		req := httptest.NewRequest(http.MethodPatch, "/catalog/PROD001", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePatch(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleDelete(t *testing.T) {
+	t.Run("deletes product", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("DeleteProduct", "PROD001").Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when product not found", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("DeleteProduct", "NOTFOUND").Return(repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/NOTFOUND", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleRestore(t *testing.T) {
+	t.Run("restores a deleted product", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			Code:  "PROD001",
+			Price: decimal.NewFromFloat(10.99),
+			Variants: []models.Variant{
+				{SKU: "SKU001A"},
+			},
+		}
+		mockRepo.On("RestoreProduct", "PROD001").Return(product, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/restore", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "PROD001", response.Code)
+		assert.False(t, response.DeletedAt.Valid)
+		assert.Equal(t, "10.99", response.Variants[0].EffectivePrice.String())
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when no deleted product has the code", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("RestoreProduct", "NOTFOUND").Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/NOTFOUND/restore", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 when the code has been reused", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("RestoreProduct", "PROD001").Return(nil, repository.ErrConflict)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/restore", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleChangeStatus(t *testing.T) {
+	t.Run("publishes a product for a scheduled window", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		publishAt := time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC)
+		unpublishAt := time.Date(2026, 12, 1, 8, 0, 0, 0, time.UTC)
+		product := &models.Product{
+			Code:        "PROD007",
+			Status:      models.StatusPublished,
+			PublishAt:   &publishAt,
+			UnpublishAt: &unpublishAt,
+		}
+		mockRepo.On("ChangeProductStatus", "PROD007", models.StatusPublished,
+			mock.MatchedBy(func(t *time.Time) bool { return t != nil && t.Equal(publishAt) }),
+			mock.MatchedBy(func(t *time.Time) bool { return t != nil && t.Equal(unpublishAt) }),
+		).Return(product, nil)
+
+		body := bytes.NewBufferString(`{"status":"published","publishAt":"2026-11-01T08:00:00Z","unpublishAt":"2026-12-01T08:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD007/status", body)
+		req.SetPathValue("code", "PROD007")
+		rec := httptest.NewRecorder()
+
+		handler.HandleChangeStatus(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, models.StatusPublished, response.Status)
+		assert.True(t, response.PublishAt.Equal(publishAt))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 for a transition the workflow does not allow", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("ChangeProductStatus", "PROD005", models.StatusPublished, mock.Anything, mock.Anything).
+			Return(nil, repository.ErrInvalidTransition)
+
+		body := bytes.NewBufferString(`{"status":"published"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD005/status", body)
+		req.SetPathValue("code", "PROD005")
+		rec := httptest.NewRecorder()
+
+		handler.HandleChangeStatus(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for unknown status", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"status":"deleted"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/status", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleChangeStatus(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "ChangeProductStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error when unpublishing before publishing", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"status":"published","publishAt":"2026-12-01T08:00:00Z","unpublishAt":"2026-11-01T08:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/status", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleChangeStatus(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "ChangeProductStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandlePriceHistory(t *testing.T) {
+	t.Run("returns price changes of product and variants", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		changes := []models.PriceChange{
+			{
+				ID:       3,
+				SKU:      "SKU001A",
+				Currency: "EUR",
+				OldPrice: decimal.NewNullDecimal(decimal.NewFromFloat(11.99)),
+				Actor:    "jane.doe",
+			},
+			{
+				ID:       2,
+				Currency: "EUR",
+				OldPrice: decimal.NewNullDecimal(decimal.NewFromFloat(10.99)),
+				NewPrice: decimal.NewNullDecimal(decimal.NewFromFloat(9.99)),
+				Actor:    "anonymous",
+			},
+		}
+		mockRepo.On("GetPriceHistory", "PROD001", repository.PublishedProducts).Return(changes, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001/price-history", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePriceHistory(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []models.PriceChange
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response, 2)
+		assert.Equal(t, "SKU001A", response[0].SKU)
+		// The variant went back to inheriting the product price
+		assert.False(t, response[0].NewPrice.Valid)
+		assert.True(t, response[1].NewPrice.Decimal.Equal(decimal.NewFromFloat(9.99)))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when product not found", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("GetPriceHistory", "NOTFOUND", repository.PublishedProducts).Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/NOTFOUND/price-history", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePriceHistory(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("finds unpublished products for admin requests", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("GetPriceHistory", "DRAFT001", repository.AllProducts).Return([]models.PriceChange{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/DRAFT001/price-history", nil)
+		req.SetPathValue("code", "DRAFT001")
+		rec := httptest.NewRecorder()
+
+		handler.HandlePriceHistory(rec, common.WithAdmin(req))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleSetPrice(t *testing.T) {
+	t.Run("sets the price in the currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		price := &models.ProductPrice{ProductID: 1, Currency: "GBP", Amount: decimal.RequireFromString("9.50")}
+		mockRepo.On("SetProductPrice", "PROD001", "GBP", mock.MatchedBy(func(amount decimal.Decimal) bool {
+			return amount.Equal(decimal.RequireFromString("9.50"))
+		}), "jane.doe").Return(price, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/prices/gbp", bytes.NewBufferString(`{"amount": "9.50"}`))
+		req.Header.Set("X-Actor", "jane.doe")
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("currency", "gbp")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSetPrice(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.ProductPrice
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "GBP", response.Currency)
+		assert.Equal(t, "9.5", response.Amount.String())
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for the base currency and unknown currencies", func(t *testing.T) {
+		for _, currency := range []string{"EUR", "JPY"} {
+			mockRepo := new(MockProductsRepository)
+			handler := NewProductHandler(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/prices/"+currency, bytes.NewBufferString(`{"amount": "9.50"}`))
+			req.SetPathValue("code", "PROD001")
+			req.SetPathValue("currency", currency)
+			rec := httptest.NewRecorder()
+
+			handler.HandleSetPrice(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, currency)
+			mockRepo.AssertNotCalled(t, "SetProductPrice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("returns error for missing or negative amount", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"amount": "-1"}`} {
+			mockRepo := new(MockProductsRepository)
+			handler := NewProductHandler(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/prices/USD", bytes.NewBufferString(body))
+			req.SetPathValue("code", "PROD001")
+			req.SetPathValue("currency", "USD")
+			rec := httptest.NewRecorder()
+
+			handler.HandleSetPrice(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+			mockRepo.AssertNotCalled(t, "SetProductPrice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("returns 404 when product not found", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("SetProductPrice", "NOTFOUND", "USD", mock.Anything, "anonymous").Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodPut, "/catalog/NOTFOUND/prices/USD", bytes.NewBufferString(`{"amount": 12}`))
+		req.SetPathValue("code", "NOTFOUND")
+		req.SetPathValue("currency", "USD")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSetPrice(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleDeletePrice(t *testing.T) {
+	t.Run("removes the currency from the price list", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("DeleteProductPrice", "PROD001", "CHF", "anonymous").Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD001/prices/CHF", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("currency", "CHF")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDeletePrice(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when the product is not priced in the currency", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("DeleteProductPrice", "PROD006", "CHF", "anonymous").Return(repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD006/prices/CHF", nil)
+		req.SetPathValue("code", "PROD006")
+		req.SetPathValue("currency", "CHF")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDeletePrice(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleAttachCategories(t *testing.T) {
+	t.Run("attaches categories and returns product", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		product := &models.Product{
+			Code:       "PROD008",
+			Price:      decimal.NewFromFloat(9.99),
+			Categories: []models.Category{{Code: "ACCESSORIES"}, {Code: "SALE"}},
+		}
+
+		mockRepo.On("AttachCategories", "PROD008", []string{"SALE"}).Return(nil)
+		mockRepo.On("GetProductByCode", "PROD008", mock.Anything).Return(product, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD008/categories", bytes.NewBufferString(`{"categories":["SALE"]}`))
+		req.SetPathValue("code", "PROD008")
+		rec := httptest.NewRecorder()
+
+		handler.HandleAttachCategories(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Product
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Categories, 2)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown category", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("AttachCategories", "PROD008", []string{"TOYS"}).Return(repository.ErrUnknownCategory)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD008/categories", bytes.NewBufferString(`{"categories":["TOYS"]}`))
+		req.SetPathValue("code", "PROD008")
+		rec := httptest.NewRecorder()
+
+		handler.HandleAttachCategories(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error without categories", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD008/categories", bytes.NewBufferString(`{"categories":[]}`))
+		req.SetPathValue("code", "PROD008")
+		rec := httptest.NewRecorder()
+
+		handler.HandleAttachCategories(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "AttachCategories", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleDetachCategory(t *testing.T) {
+	t.Run("detaches category", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("DetachCategory", "PROD008", "SALE").Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD008/categories/SALE", nil)
+		req.SetPathValue("code", "PROD008")
+		req.SetPathValue("category", "SALE")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDetachCategory(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when not assigned", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		handler := NewProductHandler(mockRepo)
+
+		mockRepo.On("DetachCategory", "PROD008", "SHOES").Return(repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD008/categories/SHOES", nil)
+		req.SetPathValue("code", "PROD008")
+		req.SetPathValue("category", "SHOES")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDetachCategory(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,138 @@
+package reservations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// defaultTTL is how long a reservation holds stock unless the request asks for another duration.
+const defaultTTL = 10 * time.Minute
+
+// CreateReservationRequest reserves a quantity of a variant. Without a warehouse the default
+// warehouse is used; TTLSeconds bounds how long the stock is held, up to an hour.
+type CreateReservationRequest struct {
+	SKU        string `json:"sku" validate:"required,max=32"`
+	Warehouse  string `json:"warehouse" validate:"max=32"`
+	Quantity   int    `json:"quantity" validate:"required,min=1"`
+	TTLSeconds int    `json:"ttlSeconds" validate:"omitempty,min=1,max=3600"`
+}
+
+var validate = validator.New()
+
+type ReservationsHandler struct {
+	repo repository.ReservationsInterface
+}
+
+func NewReservationsHandler(r repository.ReservationsInterface) *ReservationsHandler {
+	return &ReservationsHandler{
+		repo: r,
+	}
+}
+
+func (h *ReservationsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Warehouse == "" {
+		req.Warehouse = models.DefaultWarehouse
+	}
+	ttl := defaultTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	reservation, err := h.repo.CreateReservation(req.SKU, req.Warehouse, req.Quantity, ttl)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, reservation)
+}
+
+func (h *ReservationsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := reservationID(w, r)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.repo.GetReservation(id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, reservation)
+}
+
+// HandleConfirm completes the reservation once payment succeeded, taking its quantity out of stock.
+func (h *ReservationsHandler) HandleConfirm(w http.ResponseWriter, r *http.Request) {
+	id, ok := reservationID(w, r)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.repo.ConfirmReservation(id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, reservation)
+}
+
+// HandleRelease cancels the reservation, making its quantity available again.
+func (h *ReservationsHandler) HandleRelease(w http.ResponseWriter, r *http.Request) {
+	id, ok := reservationID(w, r)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.repo.ReleaseReservation(id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, reservation)
+}
+
+// reservationID reads the reservation id from the path, answering 400 when it is not a number.
+func reservationID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid reservation id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeRepositoryError maps repository errors to HTTP status codes.
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, "Reservation not found")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		api.ErrorResponse(w, http.StatusConflict, "Insufficient stock")
+	case errors.Is(err, repository.ErrReservationClosed):
+		api.ErrorResponse(w, http.StatusConflict, "Reservation is no longer active")
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,230 @@
+package reservations
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReservationsRepository is a mock implementation of ReservationsInterface
+type MockReservationsRepository struct {
+	mock.Mock
+}
+
+func (m *MockReservationsRepository) CreateReservation(sku, warehouse string, quantity int, ttl time.Duration) (*models.Reservation, error) {
+	args := m.Called(sku, warehouse, quantity, ttl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationsRepository) GetReservation(id uint) (*models.Reservation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationsRepository) ConfirmReservation(id uint) (*models.Reservation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationsRepository) ReleaseReservation(id uint) (*models.Reservation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationsRepository) ExpireReservations() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestHandleCreate(t *testing.T) {
+	t.Run("reserves stock in the default warehouse", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		reservation := &models.Reservation{ID: 1, SKU: "SKU001A", Warehouse: "default", Quantity: 2, Status: models.ReservationActive}
+		mockRepo.On("CreateReservation", "SKU001A", models.DefaultWarehouse, 2, defaultTTL).Return(reservation, nil)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001A","quantity":2}`)
+		req := httptest.NewRequest(http.MethodPost, "/reservations", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response models.Reservation
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, uint(1), response.ID)
+		assert.Equal(t, models.ReservationActive, response.Status)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("uses the requested ttl and warehouse", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		mockRepo.On("CreateReservation", "SKU004A", "outlet", 1, 2*time.Minute).Return(&models.Reservation{ID: 2}, nil)
+
+		body := bytes.NewBufferString(`{"sku":"SKU004A","warehouse":"outlet","quantity":1,"ttlSeconds":120}`)
+		req := httptest.NewRequest(http.MethodPost, "/reservations", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 when stock is not available", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		mockRepo.On("CreateReservation", "SKU001C", models.DefaultWarehouse, 1, defaultTTL).Return(nil, repository.ErrInsufficientStock)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001C","quantity":1}`)
+		req := httptest.NewRequest(http.MethodPost, "/reservations", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for invalid quantity", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001A","quantity":0}`)
+		req := httptest.NewRequest(http.MethodPost, "/reservations", body)
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateReservation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleGet(t *testing.T) {
+	t.Run("returns reservation", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		mockRepo.On("GetReservation", uint(3)).Return(&models.Reservation{ID: 3, SKU: "SKU001A"}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/reservations/3", nil)
+		req.SetPathValue("id", "3")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGet(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for invalid id", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/reservations/abc", nil)
+		req.SetPathValue("id", "abc")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGet(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetReservation", mock.Anything)
+	})
+}
+
+func TestHandleConfirm(t *testing.T) {
+	t.Run("confirms reservation", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		reservation := &models.Reservation{ID: 3, Status: models.ReservationConfirmed}
+		mockRepo.On("ConfirmReservation", uint(3)).Return(reservation, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/reservations/3/confirm", nil)
+		req.SetPathValue("id", "3")
+		rec := httptest.NewRecorder()
+
+		handler.HandleConfirm(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 for expired reservation", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		mockRepo.On("ConfirmReservation", uint(3)).Return(nil, repository.ErrReservationClosed)
+
+		req := httptest.NewRequest(http.MethodPost, "/reservations/3/confirm", nil)
+		req.SetPathValue("id", "3")
+		rec := httptest.NewRecorder()
+
+		handler.HandleConfirm(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleRelease(t *testing.T) {
+	t.Run("releases reservation", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		reservation := &models.Reservation{ID: 3, Status: models.ReservationReleased}
+		mockRepo.On("ReleaseReservation", uint(3)).Return(reservation, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/reservations/3/release", nil)
+		req.SetPathValue("id", "3")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRelease(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when reservation not found", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		handler := NewReservationsHandler(mockRepo)
+
+		mockRepo.On("ReleaseReservation", uint(9)).Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodPost, "/reservations/9/release", nil)
+		req.SetPathValue("id", "9")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRelease(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,46 @@
+package reservations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+)
+
+// Sweeper periodically expires active reservations whose time is up, so their quantity
+// becomes available again.
+type Sweeper struct {
+	repo     repository.ReservationsInterface
+	interval time.Duration
+}
+
+func NewSweeper(r repository.ReservationsInterface, interval time.Duration) *Sweeper {
+	return &Sweeper{
+		repo:     r,
+		interval: interval,
+	}
+}
+
+// Run expires stale reservations every interval until ctx is done.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Reservation sweeper stopped")
+			return
+		case <-ticker.C:
+			expired, err := s.repo.ExpireReservations()
+			if err != nil {
+				log.Printf("expiring reservations failed: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("Expired %d reservations", expired)
+			}
+		}
+	}
+}
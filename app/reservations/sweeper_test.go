@@ -0,0 +1,46 @@
+package reservations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSweeperRun(t *testing.T) {
+	t.Run("expires reservations until the context is done", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		sweeper := NewSweeper(mockRepo, time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo.On("ExpireReservations").Return(int64(1), nil).Run(func(_ mock.Arguments) {
+			cancel()
+		})
+
+		done := make(chan struct{})
+		go func() {
+			sweeper.Run(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("sweeper did not stop")
+		}
+		mockRepo.AssertCalled(t, "ExpireReservations")
+	})
+
+	t.Run("stops without sweeping when cancelled", func(t *testing.T) {
+		mockRepo := new(MockReservationsRepository)
+		sweeper := NewSweeper(mockRepo, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sweeper.Run(ctx)
+
+		assert.Empty(t, mockRepo.Calls)
+	})
+}
@@ -0,0 +1,126 @@
+package sales
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// CreateSaleRequest schedules a sale price from StartsAt until EndsAt. With a SKU the sale
+// applies to that variant only; without a currency it is in the base currency.
+type CreateSaleRequest struct {
+	SKU      string           `json:"sku" validate:"max=32"`
+	Currency string           `json:"currency"`
+	Price    *decimal.Decimal `json:"price" validate:"required"`
+	StartsAt time.Time        `json:"startsAt" validate:"required"`
+	EndsAt   time.Time        `json:"endsAt" validate:"required,gtfield=StartsAt"`
+}
+
+var validate = validator.New()
+
+type SalesHandler struct {
+	repo repository.SalesInterface
+}
+
+func NewSalesHandler(r repository.SalesInterface) *SalesHandler {
+	return &SalesHandler{
+		repo: r,
+	}
+}
+
+// HandleGetAll lists the active and upcoming sales of a product and its variants. Unpublished
+// products are only found by admin requests.
+func (h *SalesHandler) HandleGetAll(w http.ResponseWriter, r *http.Request) {
+	scope := repository.PublishedProducts
+	if common.IsAdmin(r) {
+		scope = repository.AllProducts
+	}
+
+	sales, err := h.repo.GetSales(r.PathValue("code"), scope)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, sales)
+}
+
+func (h *SalesHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Price.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: price must not be negative")
+		return
+	}
+	currency := models.BaseCurrency
+	if req.Currency != "" {
+		currency = strings.ToUpper(req.Currency)
+	}
+	if !slices.Contains(models.Currencies, currency) {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: unsupported currency "+req.Currency)
+		return
+	}
+
+	sale := &models.SalePrice{
+		SKU:      req.SKU,
+		Currency: currency,
+		Price:    *req.Price,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+
+	if err := h.repo.CreateSale(r.PathValue("code"), sale); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, sale)
+}
+
+// HandleDelete cancels a scheduled sale, or ends an active one immediately.
+func (h *SalesHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid sale id")
+		return
+	}
+
+	if err := h.repo.DeleteSale(r.PathValue("code"), uint(id)); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// writeRepositoryError maps repository errors to HTTP status codes.
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, "Product, variant or sale not found")
+	case errors.Is(err, repository.ErrSaleOverlap):
+		api.ErrorResponse(w, http.StatusConflict, "Sale overlaps another sale in the same currency")
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
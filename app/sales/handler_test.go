@@ -0,0 +1,223 @@
+package sales
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSalesRepository is a mock implementation of SalesInterface
+type MockSalesRepository struct {
+	mock.Mock
+}
+
+func (m *MockSalesRepository) GetSales(productCode string, scope repository.ProductScope) ([]models.SalePrice, error) {
+	args := m.Called(productCode, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SalePrice), args.Error(1)
+}
+
+func (m *MockSalesRepository) CreateSale(productCode string, sale *models.SalePrice) error {
+	args := m.Called(productCode, sale)
+	return args.Error(0)
+}
+
+func (m *MockSalesRepository) DeleteSale(productCode string, id uint) error {
+	args := m.Called(productCode, id)
+	return args.Error(0)
+}
+
+func TestHandleGetAll(t *testing.T) {
+	t.Run("returns the sales of a product", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		sales := []models.SalePrice{
+			{ID: 1, Currency: "EUR", Price: decimal.NewFromFloat(9.99)},
+			{ID: 2, SKU: "SKU001A", Currency: "EUR", Price: decimal.NewFromFloat(8.99)},
+		}
+		mockRepo.On("GetSales", "PROD001", repository.PublishedProducts).Return(sales, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001/sales", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []models.SalePrice
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response, 2)
+		assert.Equal(t, "SKU001A", response[1].SKU)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when product not found", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		mockRepo.On("GetSales", "NOTFOUND", repository.PublishedProducts).Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/NOTFOUND/sales", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("finds unpublished products for admin requests", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		mockRepo.On("GetSales", "DRAFT001", repository.AllProducts).Return([]models.SalePrice{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/DRAFT001/sales", nil)
+		req.SetPathValue("code", "DRAFT001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, common.WithAdmin(req))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleCreate(t *testing.T) {
+	t.Run("schedules a variant sale in a currency", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		mockRepo.On("CreateSale", "PROD001", mock.MatchedBy(func(sale *models.SalePrice) bool {
+			return sale.SKU == "SKU001A" && sale.Currency == "GBP" && sale.Price.Equal(decimal.NewFromFloat(7.5)) &&
+				sale.EndsAt.Sub(sale.StartsAt) == 14*24*time.Hour
+		})).Return(nil)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001A","currency":"gbp","price":"7.50",` +
+			`"startsAt":"2026-11-01T00:00:00Z","endsAt":"2026-11-15T00:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/sales", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("defaults to the base currency", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		mockRepo.On("CreateSale", "PROD001", mock.MatchedBy(func(sale *models.SalePrice) bool {
+			return sale.SKU == "" && sale.Currency == models.BaseCurrency
+		})).Return(nil)
+
+		body := bytes.NewBufferString(`{"price":"7.50","startsAt":"2026-11-01T00:00:00Z","endsAt":"2026-11-15T00:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/sales", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error when the sale ends before it starts", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"price":"7.50","startsAt":"2026-11-15T00:00:00Z","endsAt":"2026-11-01T00:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/sales", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateSale", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error for unsupported currency", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"currency":"JPY","price":"750","startsAt":"2026-11-01T00:00:00Z","endsAt":"2026-11-15T00:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/sales", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateSale", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 409 for overlapping sale", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		mockRepo.On("CreateSale", "PROD001", mock.Anything).Return(repository.ErrSaleOverlap)
+
+		body := bytes.NewBufferString(`{"price":"7.50","startsAt":"2026-11-01T00:00:00Z","endsAt":"2026-11-15T00:00:00Z"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/sales", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleDelete(t *testing.T) {
+	t.Run("deletes a sale", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		mockRepo.On("DeleteSale", "PROD001", uint(3)).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD001/sales/3", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("id", "3")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for invalid id", func(t *testing.T) {
+		mockRepo := new(MockSalesRepository)
+		handler := NewSalesHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD001/sales/abc", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("id", "abc")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "DeleteSale", mock.Anything, mock.Anything)
+	})
+}
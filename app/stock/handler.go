@@ -0,0 +1,114 @@
+package stock
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// AdjustStockRequest adds Delta, which may be negative, to the stock in a warehouse.
+// Without a warehouse the default warehouse is used.
+type AdjustStockRequest struct {
+	Warehouse string `json:"warehouse" validate:"max=32"`
+	Delta     *int   `json:"delta" validate:"required"`
+}
+
+// SetStockRequest overwrites the stock in the warehouse given in the path.
+type SetStockRequest struct {
+	Quantity *int `json:"quantity" validate:"required,min=0"`
+}
+
+var validate = validator.New()
+
+type StockHandler struct {
+	repo repository.StockInterface
+}
+
+func NewStockHandler(r repository.StockInterface) *StockHandler {
+	return &StockHandler{
+		repo: r,
+	}
+}
+
+func (h *StockHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	level, err := h.repo.GetStock(r.PathValue("sku"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, level)
+}
+
+// HandleAdjust atomically changes the stock of a variant by a delta. Decrements that would
+// take the stock below what active reservations hold are refused with 409.
+func (h *StockHandler) HandleAdjust(w http.ResponseWriter, r *http.Request) {
+	var req AdjustStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Warehouse == "" {
+		req.Warehouse = models.DefaultWarehouse
+	}
+
+	level, err := h.repo.AdjustStock(r.PathValue("sku"), req.Warehouse, *req.Delta)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, level)
+}
+
+// HandleSet overwrites the stock of a variant in a warehouse. A quantity below what active
+// reservations hold is refused with 409.
+func (h *StockHandler) HandleSet(w http.ResponseWriter, r *http.Request) {
+	var req SetStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	warehouse := r.PathValue("warehouse")
+	if len(warehouse) > 32 {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: warehouse is too long")
+		return
+	}
+
+	level, err := h.repo.SetStock(r.PathValue("sku"), warehouse, *req.Quantity)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, level)
+}
+
+// writeRepositoryError maps repository errors to HTTP status codes.
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, "Variant not found")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		api.ErrorResponse(w, http.StatusConflict, "Insufficient stock")
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,202 @@
+package stock
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStockRepository is a mock implementation of StockInterface
+type MockStockRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockRepository) GetStock(sku string) (*repository.StockLevel, error) {
+	args := m.Called(sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.StockLevel), args.Error(1)
+}
+
+func (m *MockStockRepository) AdjustStock(sku, warehouse string, delta int) (*repository.StockLevel, error) {
+	args := m.Called(sku, warehouse, delta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.StockLevel), args.Error(1)
+}
+
+func (m *MockStockRepository) SetStock(sku, warehouse string, quantity int) (*repository.StockLevel, error) {
+	args := m.Called(sku, warehouse, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.StockLevel), args.Error(1)
+}
+
+func TestHandleGet(t *testing.T) {
+	t.Run("returns stock per warehouse", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		level := &repository.StockLevel{
+			SKU:       "SKU004A",
+			Quantity:  5,
+			Available: 5,
+			InStock:   true,
+			Warehouses: []models.VariantStock{
+				{Warehouse: "default", Quantity: 2},
+				{Warehouse: "outlet", Quantity: 3},
+			},
+		}
+
+		mockRepo.On("GetStock", "SKU004A").Return(level, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/SKU004A/stock", nil)
+		req.SetPathValue("sku", "SKU004A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGet(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response repository.StockLevel
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, 5, response.Quantity)
+		assert.True(t, response.InStock)
+		assert.Len(t, response.Warehouses, 2)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when SKU not found", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		mockRepo.On("GetStock", "UNKNOWN").Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/UNKNOWN/stock", nil)
+		req.SetPathValue("sku", "UNKNOWN")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGet(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleAdjust(t *testing.T) {
+	t.Run("decrements stock in the default warehouse", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		level := &repository.StockLevel{SKU: "SKU001A", Quantity: 10, Available: 10, InStock: true}
+		mockRepo.On("AdjustStock", "SKU001A", models.DefaultWarehouse, -2).Return(level, nil)
+
+		body := bytes.NewBufferString(`{"delta":-2}`)
+		req := httptest.NewRequest(http.MethodPost, "/variants/SKU001A/stock/adjustments", body)
+		req.SetPathValue("sku", "SKU001A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleAdjust(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 for insufficient stock", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		mockRepo.On("AdjustStock", "SKU001C", "outlet", -1).Return(nil, repository.ErrInsufficientStock)
+
+		body := bytes.NewBufferString(`{"warehouse":"outlet","delta":-1}`)
+		req := httptest.NewRequest(http.MethodPost, "/variants/SKU001C/stock/adjustments", body)
+		req.SetPathValue("sku", "SKU001C")
+		rec := httptest.NewRecorder()
+
+		handler.HandleAdjust(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error without delta", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"warehouse":"outlet"}`)
+		req := httptest.NewRequest(http.MethodPost, "/variants/SKU001A/stock/adjustments", body)
+		req.SetPathValue("sku", "SKU001A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleAdjust(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleSet(t *testing.T) {
+	t.Run("sets stock in a warehouse", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		level := &repository.StockLevel{SKU: "SKU001A", Quantity: 20, Available: 20, InStock: true}
+		mockRepo.On("SetStock", "SKU001A", "outlet", 8).Return(level, nil)
+
+		body := bytes.NewBufferString(`{"quantity":8}`)
+		req := httptest.NewRequest(http.MethodPut, "/variants/SKU001A/stock/outlet", body)
+		req.SetPathValue("sku", "SKU001A")
+		req.SetPathValue("warehouse", "outlet")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSet(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for negative quantity", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"quantity":-1}`)
+		req := httptest.NewRequest(http.MethodPut, "/variants/SKU001A/stock/outlet", body)
+		req.SetPathValue("sku", "SKU001A")
+		req.SetPathValue("warehouse", "outlet")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSet(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "SetStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 409 below the reserved quantity", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		handler := NewStockHandler(mockRepo)
+
+		mockRepo.On("SetStock", "SKU001A", "outlet", 1).Return(nil, repository.ErrInsufficientStock)
+
+		body := bytes.NewBufferString(`{"quantity":1}`)
+		req := httptest.NewRequest(http.MethodPut, "/variants/SKU001A/stock/outlet", body)
+		req.SetPathValue("sku", "SKU001A")
+		req.SetPathValue("warehouse", "outlet")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSet(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
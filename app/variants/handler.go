@@ -0,0 +1,374 @@
+package variants
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+type VariantsResponse struct {
+	Variants []models.Variant `json:"variants"`
+}
+
+// SKUsResponse lists the variants found for a batch of SKUs, and the SKUs that were not found.
+type SKUsResponse struct {
+	Variants []models.Variant `json:"variants"`
+	Missing  []string         `json:"missing"`
+}
+
+// maxSKUs caps the number of SKUs resolved in a single batch request.
+const maxSKUs = 100
+
+// CreateVariantRequest describes a new variant. Without a price the variant inherits the product price.
+// Options maps each option of the product to the variant's value, e.g. {"Size": "M"}; without
+// a name the variant is named after its option values.
+type CreateVariantRequest struct {
+	Name    string            `json:"name" validate:"required_without=Options,max=256"`
+	SKU     string            `json:"sku" validate:"required,max=32"`
+	Price   *decimal.Decimal  `json:"price"`
+	Options map[string]string `json:"options" validate:"dive,keys,required,max=64,endkeys,required,max=64"`
+}
+
+// UpdateVariantRequest replaces the editable fields of a variant. The SKU is taken from the path.
+type UpdateVariantRequest struct {
+	Name    string            `json:"name" validate:"required_without=Options,max=256"`
+	Price   *decimal.Decimal  `json:"price"`
+	Options map[string]string `json:"options" validate:"dive,keys,required,max=64,endkeys,required,max=64"`
+}
+
+// SetPriceRequest sets the price of a variant in a currency other than the base currency.
+type SetPriceRequest struct {
+	Amount *decimal.Decimal `json:"amount" validate:"required"`
+}
+
+var validate = validator.New()
+
+type VariantsHandler struct {
+	repo repository.VariantsInterface
+}
+
+func NewVariantsHandler(r repository.VariantsInterface) *VariantsHandler {
+	return &VariantsHandler{
+		repo: r,
+	}
+}
+
+func (h *VariantsHandler) HandleGetAll(w http.ResponseWriter, r *http.Request) {
+	currency, err := common.ParseCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variants, err := h.repo.GetVariantsByProductCode(r.PathValue("code"), scope(r))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.ErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Resolve prices in the requested currency; the product is known from the path
+	for i := range variants {
+		if err := variants[i].ApplyProductCurrency(currency); err != nil {
+			api.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		variants[i].Product = nil
+	}
+
+	api.OKResponse(w, VariantsResponse{Variants: variants})
+}
+
+func (h *VariantsHandler) HandleGetBySKU(w http.ResponseWriter, r *http.Request) {
+	sku := r.PathValue("sku")
+	if sku == "" {
+		api.ErrorResponse(w, http.StatusBadRequest, "SKU is required")
+		return
+	}
+
+	currency, err := common.ParseCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variants, err := h.repo.GetVariantsBySKU([]string{sku}, scope(r))
+	if err != nil {
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(variants) == 0 {
+		api.ErrorResponse(w, http.StatusNotFound, "Variant not found")
+		return
+	}
+
+	variant := variants[0]
+	if err := variant.ApplyProductCurrency(currency); err != nil {
+		api.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	api.OKResponse(w, variant)
+}
+
+func (h *VariantsHandler) HandleGetBySKUs(w http.ResponseWriter, r *http.Request) {
+	skus := r.URL.Query()["sku"]
+	if len(skus) == 0 {
+		api.ErrorResponse(w, http.StatusBadRequest, "At least one sku is required")
+		return
+	}
+	if len(skus) > maxSKUs {
+		api.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("At most %d skus are allowed", maxSKUs))
+		return
+	}
+
+	currency, err := common.ParseCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variants, err := h.repo.GetVariantsBySKU(skus, scope(r))
+	if err != nil {
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Return variants in request order and report the SKUs that were not found
+	bySKU := make(map[string]models.Variant, len(variants))
+	for _, variant := range variants {
+		if err := variant.ApplyProductCurrency(currency); err != nil {
+			api.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		bySKU[variant.SKU] = variant
+	}
+
+	response := SKUsResponse{
+		Variants: make([]models.Variant, 0, len(variants)),
+		Missing:  []string{},
+	}
+	for _, sku := range skus {
+		variant, ok := bySKU[sku]
+		if !ok {
+			response.Missing = append(response.Missing, sku)
+			continue
+		}
+		response.Variants = append(response.Variants, variant)
+	}
+
+	api.OKResponse(w, response)
+}
+
+func (h *VariantsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateVariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Price != nil && req.Price.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: price must not be negative")
+		return
+	}
+
+	variant := &models.Variant{
+		Name:    req.Name,
+		SKU:     req.SKU,
+		Price:   nullablePrice(req.Price),
+		Options: options(req.Options),
+	}
+
+	if err := h.repo.CreateVariant(r.PathValue("code"), variant, common.ParseActor(r)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.ErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withEffectivePrice(variant)
+	api.CreatedResponse(w, variant)
+}
+
+func (h *VariantsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateVariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Price != nil && req.Price.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: price must not be negative")
+		return
+	}
+
+	variant, err := h.repo.GetVariant(r.PathValue("code"), r.PathValue("sku"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	variant.Name = req.Name
+	variant.Price = nullablePrice(req.Price)
+	variant.Options = options(req.Options)
+
+	if err := h.repo.UpdateVariant(variant, common.ParseActor(r)); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withEffectivePrice(variant)
+	api.OKResponse(w, variant)
+}
+
+func (h *VariantsHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteVariant(r.PathValue("code"), r.PathValue("sku")); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// HandleRestore restores a deleted variant of the product, refusing with 409 if its SKU has been reused.
+func (h *VariantsHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	variant, err := h.repo.RestoreVariant(r.PathValue("code"), r.PathValue("sku"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	withEffectivePrice(variant)
+	api.OKResponse(w, variant)
+}
+
+// HandleSetPrice sets the price of a variant in the currency from the path. Only variants with a
+// price of their own have price lists; the others inherit the product price and are refused with 409.
+func (h *VariantsHandler) HandleSetPrice(w http.ResponseWriter, r *http.Request) {
+	currency, err := common.ParsePriceListCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req SetPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	if req.Amount.IsNegative() {
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: amount must not be negative")
+		return
+	}
+
+	price, err := h.repo.SetVariantPrice(r.PathValue("code"), r.PathValue("sku"), currency, *req.Amount, common.ParseActor(r))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.OKResponse(w, price)
+}
+
+// HandleDeletePrice removes the currency from the price list of a variant.
+func (h *VariantsHandler) HandleDeletePrice(w http.ResponseWriter, r *http.Request) {
+	currency, err := common.ParsePriceListCurrency(r, models.Currencies, models.BaseCurrency)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.repo.DeleteVariantPrice(r.PathValue("code"), r.PathValue("sku"), currency, common.ParseActor(r)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.ErrorResponse(w, http.StatusNotFound, "Variant not found or not priced in "+currency)
+			return
+		}
+		writeRepositoryError(w, err)
+		return
+	}
+
+	api.NoContentResponse(w)
+}
+
+// scope shows variants of unpublished products to admin requests only.
+func scope(r *http.Request) repository.ProductScope {
+	if common.IsAdmin(r) {
+		return repository.AllProducts
+	}
+	return repository.PublishedProducts
+}
+
+// nullablePrice stores a missing price as NULL, which means the variant inherits the product price.
+func nullablePrice(price *decimal.Decimal) decimal.NullDecimal {
+	if price == nil {
+		return decimal.NullDecimal{}
+	}
+	return decimal.NewNullDecimal(*price)
+}
+
+// options converts requested option values to models; the repository orders them.
+func options(values map[string]string) []models.VariantOption {
+	result := make([]models.VariantOption, 0, len(values))
+	for name, value := range values {
+		result = append(result, models.VariantOption{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// withEffectivePrice resolves the variant price from its product. The product itself is
+// left out of responses nested under /catalog/{code}, where it is already known.
+func withEffectivePrice(variant *models.Variant) {
+	variant.ApplyProductPrices()
+	variant.Product = nil
+}
+
+// writeRepositoryError maps repository errors to HTTP status codes.
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, "Variant not found")
+	case errors.Is(err, repository.ErrConflict):
+		api.ErrorResponse(w, http.StatusConflict, "Variant SKU already exists")
+	case errors.Is(err, repository.ErrDuplicateOptions):
+		api.ErrorResponse(w, http.StatusConflict, "Another variant has the same options")
+	case errors.Is(err, repository.ErrPriceInherited):
+		api.ErrorResponse(w, http.StatusConflict, "Variant inherits the product price; set a price of its own first")
+	case errors.Is(err, repository.ErrInvalidOptions):
+		api.ErrorResponse(w, http.StatusBadRequest, "Validation error: "+err.Error())
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,822 @@
+package variants
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockVariantsRepository is a mock implementation of VariantsInterface
+type MockVariantsRepository struct {
+	mock.Mock
+}
+
+func (m *MockVariantsRepository) GetVariantsByProductCode(code string, scope repository.ProductScope) ([]models.Variant, error) {
+	args := m.Called(code, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Variant), args.Error(1)
+}
+
+func (m *MockVariantsRepository) GetVariant(productCode, sku string) (*models.Variant, error) {
+	args := m.Called(productCode, sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Variant), args.Error(1)
+}
+
+func (m *MockVariantsRepository) GetVariantsBySKU(skus []string, scope repository.ProductScope) ([]models.Variant, error) {
+	args := m.Called(skus, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Variant), args.Error(1)
+}
+
+func (m *MockVariantsRepository) CreateVariant(productCode string, variant *models.Variant, actor string) error {
+	args := m.Called(productCode, variant, actor)
+	return args.Error(0)
+}
+
+func (m *MockVariantsRepository) UpdateVariant(variant *models.Variant, actor string) error {
+	args := m.Called(variant, actor)
+	return args.Error(0)
+}
+
+func (m *MockVariantsRepository) DeleteVariant(productCode, sku string) error {
+	args := m.Called(productCode, sku)
+	return args.Error(0)
+}
+
+func (m *MockVariantsRepository) RestoreVariant(productCode, sku string) (*models.Variant, error) {
+	args := m.Called(productCode, sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Variant), args.Error(1)
+}
+
+func (m *MockVariantsRepository) SetVariantPrice(productCode, sku, currency string, amount decimal.Decimal, actor string) (*models.VariantPrice, error) {
+	args := m.Called(productCode, sku, currency, amount, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.VariantPrice), args.Error(1)
+}
+
+func (m *MockVariantsRepository) DeleteVariantPrice(productCode, sku, currency, actor string) error {
+	args := m.Called(productCode, sku, currency, actor)
+	return args.Error(0)
+}
+
+func TestHandleGetAll(t *testing.T) {
+	t.Run("returns variants of a product", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variants := []models.Variant{
+			{Name: "Variant A", SKU: "SKU001A"},
+			{Name: "Variant B", SKU: "SKU001B"},
+		}
+
+		mockRepo.On("GetVariantsByProductCode", "PROD001", repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001/variants", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response VariantsResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Variants, 2)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns prices in the requested currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		product := &models.Product{
+			Code:   "PROD001",
+			Price:  decimal.NewFromFloat(10.99),
+			Prices: []models.ProductPrice{{Currency: "GBP", Amount: decimal.NewFromFloat(9.45)}},
+		}
+		variants := []models.Variant{
+			{
+				SKU:     "SKU001A",
+				Price:   decimal.NewNullDecimal(decimal.NewFromFloat(11.99)),
+				Prices:  []models.VariantPrice{{Currency: "GBP", Amount: decimal.NewFromFloat(10.31)}},
+				Product: product,
+			},
+			{SKU: "SKU001B", Product: product},
+		}
+
+		mockRepo.On("GetVariantsByProductCode", "PROD001", repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001/variants?currency=gbp", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response VariantsResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "GBP", response.Variants[0].Currency)
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(10.31)))
+		assert.True(t, response.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(9.45)))
+		assert.Nil(t, response.Variants[0].Product)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 422 when a variant has no price in the currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		product := &models.Product{
+			Code:   "PROD001",
+			Price:  decimal.NewFromFloat(10.99),
+			Prices: []models.ProductPrice{{Currency: "USD", Amount: decimal.NewFromFloat(11.98)}},
+		}
+		variants := []models.Variant{
+			{SKU: "SKU001A", Price: decimal.NewNullDecimal(decimal.NewFromFloat(11.99)), Product: product},
+		}
+
+		mockRepo.On("GetVariantsByProductCode", "PROD001", repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001/variants", nil)
+		req.Header.Set("Accept-Currency", "USD")
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/PROD001/variants?currency=JPY", nil)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetVariantsByProductCode", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 404 when product not found", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("GetVariantsByProductCode", "NOTFOUND", repository.PublishedProducts).Return(nil, repository.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/catalog/NOTFOUND/variants", nil)
+		req.SetPathValue("code", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("shows variants of unpublished products to admin requests only", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("GetVariantsByProductCode", "PROD008", repository.AllProducts).Return([]models.Variant{{SKU: "SKU008A"}}, nil)
+
+		req := common.WithAdmin(httptest.NewRequest(http.MethodGet, "/catalog/PROD008/variants", nil))
+		req.SetPathValue("code", "PROD008")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetAll(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleGetBySKU(t *testing.T) {
+	t.Run("returns variant with product and inherited price", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variants := []models.Variant{
+			{
+				Name: "Variant B",
+				SKU:  "SKU001B",
+				Product: &models.Product{
+					Code:     "PROD001",
+					Price:    decimal.NewFromFloat(10.99),
+					Category: &models.Category{Code: "CLOTHING", Name: "Clothing"},
+				},
+			},
+		}
+
+		mockRepo.On("GetVariantsBySKU", []string{"SKU001B"}, repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/SKU001B", nil)
+		req.SetPathValue("sku", "SKU001B")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Variant
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "SKU001B", response.SKU)
+		assert.NotNil(t, response.Product)
+		assert.Equal(t, "CLOTHING", response.Product.Category.Code)
+		assert.True(t, response.EffectivePrice.Equal(decimal.NewFromFloat(10.99)))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the product sale on an inherited price", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variants := []models.Variant{
+			{
+				SKU: "SKU002A",
+				Product: &models.Product{
+					Code:  "PROD002",
+					Price: decimal.NewFromFloat(20),
+					Sales: []models.SalePrice{{Currency: "EUR", Price: decimal.NewFromFloat(16)}},
+				},
+			},
+		}
+
+		mockRepo.On("GetVariantsBySKU", []string{"SKU002A"}, repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/SKU002A", nil)
+		req.SetPathValue("sku", "SKU002A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Variant
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.True(t, response.EffectivePrice.Equal(decimal.NewFromFloat(16)))
+		assert.True(t, response.OriginalPrice.Equal(decimal.NewFromFloat(20)))
+		assert.Equal(t, 20, response.DiscountPercent)
+		assert.True(t, response.PriceInherited)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the product sale in the requested currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variants := []models.Variant{
+			{
+				SKU: "SKU002A",
+				Product: &models.Product{
+					Code:   "PROD002",
+					Price:  decimal.NewFromFloat(20),
+					Prices: []models.ProductPrice{{Currency: "CHF", Amount: decimal.NewFromFloat(19)}},
+					Sales: []models.SalePrice{
+						{Currency: "EUR", Price: decimal.NewFromFloat(16)},
+						{Currency: "CHF", Price: decimal.NewFromFloat(15.2)},
+					},
+				},
+			},
+		}
+
+		mockRepo.On("GetVariantsBySKU", []string{"SKU002A"}, repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/SKU002A?currency=CHF", nil)
+		req.SetPathValue("sku", "SKU002A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Variant
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "CHF", response.Currency)
+		assert.True(t, response.EffectivePrice.Equal(decimal.NewFromFloat(15.2)))
+		assert.True(t, response.OriginalPrice.Equal(decimal.NewFromFloat(19)))
+		assert.Equal(t, "CHF", response.Product.Currency)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 422 when the product has no price in the currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variants := []models.Variant{
+			{SKU: "SKU006A", Product: &models.Product{Code: "PROD006", Price: decimal.NewFromFloat(30)}},
+		}
+
+		mockRepo.On("GetVariantsBySKU", []string{"SKU006A"}, repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/SKU006A?currency=GBP", nil)
+		req.SetPathValue("sku", "SKU006A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when SKU not found", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("GetVariantsBySKU", []string{"NOTFOUND"}, repository.PublishedProducts).Return([]models.Variant{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/NOTFOUND", nil)
+		req.SetPathValue("sku", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("looks up SKUs of unpublished products for admin requests", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variants := []models.Variant{{SKU: "SKU008A", Product: &models.Product{Code: "PROD008", Price: decimal.NewFromFloat(50)}}}
+		mockRepo.On("GetVariantsBySKU", []string{"SKU008A"}, repository.PublishedProducts).Return([]models.Variant{}, nil)
+		mockRepo.On("GetVariantsBySKU", []string{"SKU008A"}, repository.AllProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants/SKU008A?admin=true", nil)
+		req.SetPathValue("sku", "SKU008A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, "a query flag does not make an admin request")
+
+		req = common.WithAdmin(httptest.NewRequest(http.MethodGet, "/variants/SKU008A", nil))
+		req.SetPathValue("sku", "SKU008A")
+		rec = httptest.NewRecorder()
+
+		handler.HandleGetBySKU(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleGetBySKUs(t *testing.T) {
+	t.Run("returns variants in request order and missing SKUs", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		product := &models.Product{Code: "PROD001", Price: decimal.NewFromFloat(10.99)}
+		variants := []models.Variant{
+			{SKU: "SKU001A", Price: decimal.NewNullDecimal(decimal.NewFromFloat(11.99)), Product: product},
+			{SKU: "SKU001B", Product: product},
+		}
+
+		skus := []string{"SKU001B", "NOTFOUND", "SKU001A"}
+		mockRepo.On("GetVariantsBySKU", skus, repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants?sku=SKU001B&sku=NOTFOUND&sku=SKU001A", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKUs(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response SKUsResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Len(t, response.Variants, 2)
+		assert.Equal(t, "SKU001B", response.Variants[0].SKU)
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(10.99)))
+		assert.True(t, response.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(11.99)))
+		assert.Equal(t, []string{"NOTFOUND"}, response.Missing)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns prices in the requested currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		product := &models.Product{
+			Code:   "PROD001",
+			Price:  decimal.NewFromFloat(10.99),
+			Prices: []models.ProductPrice{{Currency: "USD", Amount: decimal.NewFromFloat(11.98)}},
+		}
+		variants := []models.Variant{
+			{
+				SKU:     "SKU001A",
+				Price:   decimal.NewNullDecimal(decimal.NewFromFloat(11.99)),
+				Prices:  []models.VariantPrice{{Currency: "USD", Amount: decimal.NewFromFloat(13.07)}},
+				Product: product,
+			},
+			{SKU: "SKU001B", Product: product},
+		}
+
+		mockRepo.On("GetVariantsBySKU", []string{"SKU001A", "SKU001B"}, repository.PublishedProducts).Return(variants, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants?sku=SKU001A&sku=SKU001B&currency=USD", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKUs(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response SKUsResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.True(t, response.Variants[0].EffectivePrice.Equal(decimal.NewFromFloat(13.07)))
+		assert.True(t, response.Variants[1].EffectivePrice.Equal(decimal.NewFromFloat(11.98)))
+		assert.Equal(t, "USD", response.Variants[1].Currency)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for unknown currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants?sku=SKU001A&currency=JPY", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKUs(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetVariantsBySKU", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error without skus", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/variants", nil)
+		rec := httptest.NewRecorder()
+
+		handler.HandleGetBySKUs(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetVariantsBySKU", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleCreate(t *testing.T) {
+	t.Run("creates new variant", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("CreateVariant", "PROD001", mock.MatchedBy(func(v *models.Variant) bool {
+			return v.SKU == "SKU001D" && v.Name == "Variant D" &&
+				v.Price.Valid && v.Price.Decimal.Equal(decimal.NewFromFloat(12.99))
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"name":"Variant D","sku":"SKU001D","price":"12.99"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for missing required fields", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"name":"Variant D"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateVariant", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns 409 for duplicate SKU", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("CreateVariant", "PROD001", mock.Anything, mock.Anything).Return(repository.ErrConflict)
+
+		body := bytes.NewBufferString(`{"name":"Variant A","sku":"SKU001A"}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("creates variant with options and no name", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("CreateVariant", "PROD001", mock.MatchedBy(func(v *models.Variant) bool {
+			return v.SKU == "SKU001D" && v.Name == "" && assert.ObjectsAreEqual([]models.VariantOption{
+				{Name: "Color", Value: "White"},
+				{Name: "Size", Value: "L"},
+			}, v.Options)
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001D","options":{"Size":"L","Color":"White"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 for duplicate option combination", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("CreateVariant", "PROD001", mock.Anything, mock.Anything).Return(repository.ErrDuplicateOptions)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001D","options":{"Size":"S","Color":"Black"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "same options")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 400 for options not matching the product", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("CreateVariant", "PROD001", mock.Anything, mock.Anything).Return(repository.ErrInvalidOptions)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001D","options":{"Material":"Wool"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for empty option value", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		body := bytes.NewBufferString(`{"sku":"SKU001D","options":{"Size":""}}`)
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants", body)
+		req.SetPathValue("code", "PROD001")
+		rec := httptest.NewRecorder()
+
+		handler.HandleCreate(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateVariant", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleUpdate(t *testing.T) {
+	t.Run("updates name and price", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variant := &models.Variant{
+			ID:        1,
+			ProductID: 1,
+			Name:      "Variant A",
+			SKU:       "SKU001A",
+			Price:     decimal.NewNullDecimal(decimal.NewFromFloat(11.99)),
+			Product:   &models.Product{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)},
+		}
+
+		mockRepo.On("GetVariant", "PROD001", "SKU001A").Return(variant, nil)
+		mockRepo.On("UpdateVariant", mock.MatchedBy(func(v *models.Variant) bool {
+			return v.Name == "Small" && !v.Price.Valid
+		}), mock.Anything).Return(nil)
+
+		body := bytes.NewBufferString(`{"name":"Small"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/variants/SKU001A", body)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Variant
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.True(t, response.PriceInherited)
+		assert.True(t, response.EffectivePrice.Equal(decimal.NewFromFloat(10.99)))
+		assert.Nil(t, response.Product)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 404 when variant not found", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("GetVariant", "PROD001", "NOTFOUND").Return(nil, repository.ErrNotFound)
+
+		body := bytes.NewBufferString(`{"name":"Small"}`)
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/variants/NOTFOUND", body)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "NOTFOUND")
+		rec := httptest.NewRecorder()
+
+		handler.HandleUpdate(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleDelete(t *testing.T) {
+	t.Run("deletes variant", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("DeleteVariant", "PROD001", "SKU001A").Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD001/variants/SKU001A", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDelete(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleRestore(t *testing.T) {
+	t.Run("restores a deleted variant", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		variant := &models.Variant{
+			SKU:     "SKU001A",
+			Product: &models.Product{Code: "PROD001", Price: decimal.NewFromFloat(10.99)},
+		}
+		mockRepo.On("RestoreVariant", "PROD001", "SKU001A").Return(variant, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants/SKU001A/restore", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Variant
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "SKU001A", response.SKU)
+		assert.Equal(t, "10.99", response.EffectivePrice.String())
+		assert.Nil(t, response.Product)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 when the SKU has been reused", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("RestoreVariant", "PROD001", "SKU001A").Return(nil, repository.ErrConflict)
+
+		req := httptest.NewRequest(http.MethodPost, "/catalog/PROD001/variants/SKU001A/restore", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		rec := httptest.NewRecorder()
+
+		handler.HandleRestore(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandleSetPrice(t *testing.T) {
+	t.Run("sets the price in the currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		price := &models.VariantPrice{VariantID: 1, Currency: "USD", Amount: decimal.RequireFromString("13.00")}
+		mockRepo.On("SetVariantPrice", "PROD001", "SKU001A", "USD", mock.MatchedBy(func(amount decimal.Decimal) bool {
+			return amount.Equal(decimal.RequireFromString("13"))
+		}), "anonymous").Return(price, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/variants/SKU001A/prices/usd", bytes.NewBufferString(`{"amount": 13}`))
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		req.SetPathValue("currency", "usd")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSetPrice(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.VariantPrice
+		json.NewDecoder(rec.Body).Decode(&response)
+		assert.Equal(t, "USD", response.Currency)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns 409 when the variant inherits the product price", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("SetVariantPrice", "PROD001", "SKU001B", "USD", mock.Anything, "anonymous").Return(nil, repository.ErrPriceInherited)
+
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/variants/SKU001B/prices/USD", bytes.NewBufferString(`{"amount": 13}`))
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001B")
+		req.SetPathValue("currency", "USD")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSetPrice(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for the base currency", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		req := httptest.NewRequest(http.MethodPut, "/catalog/PROD001/variants/SKU001A/prices/EUR", bytes.NewBufferString(`{"amount": 13}`))
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		req.SetPathValue("currency", "EUR")
+		rec := httptest.NewRecorder()
+
+		handler.HandleSetPrice(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "SetVariantPrice", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleDeletePrice(t *testing.T) {
+	t.Run("removes the currency from the price list", func(t *testing.T) {
+		mockRepo := new(MockVariantsRepository)
+		handler := NewVariantsHandler(mockRepo)
+
+		mockRepo.On("DeleteVariantPrice", "PROD001", "SKU001A", "GBP", "jane.doe").Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/catalog/PROD001/variants/SKU001A/prices/GBP", nil)
+		req.Header.Set("X-Actor", "jane.doe")
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		req.SetPathValue("currency", "GBP")
+		rec := httptest.NewRecorder()
+
+		handler.HandleDeletePrice(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
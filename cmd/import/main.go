@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/internal/importer"
+)
+
+func main() {
+	format := flag.String("format", "", "file format, csv or ndjson; taken from the file extension by default")
+	dryRun := flag.Bool("dry-run", false, "validate and report what would change without changing anything")
+	batchSize := flag.Int("batch-size", 0, "commit every N products separately; 0 imports everything in one transaction")
+	actor := flag.String("actor", "import", "author recorded for the price changes")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: import [flags] <file>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *batchSize < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file
+	if err := godotenv.Load(".env"); err != nil {
+		log.Fatalf("Error loading .env file: %s", err)
+	}
+
+	path := flag.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("opening import file failed: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := importer.Parse(file, *format)
+	if err != nil {
+		log.Fatalf("reading import file failed: %v", err)
+	}
+
+	// Initialize database connection
+	db, close := database.New(
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_PORT"),
+	)
+	defer close()
+
+	report, err := importer.New(db).Import(rows, importer.Options{
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+		Actor:     *actor,
+	})
+	if err != nil {
+		log.Printf("import failed: %v", err)
+		return
+	}
+
+	// Print one line per row, then the totals
+	for _, row := range report.Rows {
+		fmt.Printf("%5d  %-32s  %-32s  %s\n", row.Line, row.Code, row.SKU, row.Status)
+		for _, message := range row.Errors {
+			fmt.Printf("       %s\n", message)
+		}
+	}
+	prefix := ""
+	if report.DryRun {
+		prefix = "Dry run, nothing changed: "
+	}
+	log.Printf("%s%d created, %d updated, %d invalid, %d failed, %d skipped", prefix,
+		report.Created, report.Updated, report.Invalid, report.Failed, report.Skipped)
+	if !report.Succeeded() {
+		os.Exit(1)
+	}
+}
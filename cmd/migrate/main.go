@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/internal/migrate"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [steps]    revert the last applied migration, or the last steps ones
+  goto <version>  apply or revert migrations until exactly those up to version are applied
+  status          list migrations and whether they are applied
+  baseline <version>
+                  record migrations up to version as applied without running them, to adopt
+                  a database created before migrations were tracked`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	// Load environment variables from .env file
+	if err := godotenv.Load(".env"); err != nil {
+		log.Fatalf("Error loading .env file: %s", err)
+	}
+
+	migrations, err := migrate.Load(os.DirFS(os.Getenv("POSTGRES_SQL_DIR")))
+	if err != nil {
+		log.Fatalf("loading migrations failed: %v", err)
+	}
+
+	// Initialize database connection
+	db, close := database.New(
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_PORT"),
+	)
+	defer close()
+
+	migrator := migrate.New(db, migrations, log.Default())
+
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "up":
+		_, err = migrator.Up()
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps %q", args[0])
+			}
+		}
+		_, err = migrator.Down(steps)
+	case "goto":
+		if len(args) == 0 {
+			log.Fatal(usage)
+		}
+		version, convErr := strconv.Atoi(args[0])
+		if convErr != nil || version < 0 {
+			log.Fatalf("invalid version %q", args[0])
+		}
+		_, err = migrator.Goto(version)
+	case "baseline":
+		if len(args) == 0 {
+			log.Fatal(usage)
+		}
+		version, convErr := strconv.Atoi(args[0])
+		if convErr != nil || version < 1 {
+			log.Fatalf("invalid version %q", args[0])
+		}
+		_, err = migrator.Baseline(version)
+	case "status":
+		err = printStatus(migrator)
+	default:
+		log.Fatal(usage)
+	}
+	if err != nil {
+		log.Fatalf("%s failed: %v", os.Args[1], err)
+	}
+}
+
+// printStatus writes one line per migration: its version, name and state.
+func printStatus(migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Missing:
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05") + ", file missing"
+		case status.Modified:
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05") + ", file modified"
+		case status.AppliedAt != nil:
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%03d  %-40s  %s\n", status.Version, status.Name, state)
+	}
+	return nil
+}
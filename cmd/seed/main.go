@@ -1,22 +1,37 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/internal/fixtures"
 )
 
 func main() {
+	only := flag.String("only", "", "comma separated fixture kinds to seed: "+strings.Join(fixtures.Kinds, ", "))
+	dryRun := flag.Bool("dry-run", false, "report what would change without changing anything")
+	reset := flag.Bool("reset", false, "delete the records the fixtures define before seeding them")
+	flag.Parse()
+
 	// Load environment variables from .env file
 	if err := godotenv.Load(".env"); err != nil {
 		log.Fatalf("Error loading .env file: %s", err)
 	}
 
+	loaded, err := fixtures.Load(os.DirFS(os.Getenv("FIXTURES_DIR")))
+	if err != nil {
+		log.Fatalf("loading fixtures failed: %v", err)
+	}
+
+	options := fixtures.Options{DryRun: *dryRun, Reset: *reset}
+	if *only != "" {
+		options.Only = strings.Split(*only, ",")
+	}
+
 	// Initialize database connection
 	db, close := database.New(
 		os.Getenv("POSTGRES_USER"),
@@ -26,37 +41,18 @@ func main() {
 	)
 	defer close()
 
-	dir := os.Getenv("POSTGRES_SQL_DIR")
-	files, err := os.ReadDir(dir)
+	// Upsert the fixtures in a single transaction, rolled back on a dry run
+	report, err := fixtures.Seed(db, loaded, options, log.Default())
 	if err != nil {
-		log.Fatalf("reading directory failed: %v", err)
+		log.Fatalf("seeding failed: %v", err)
 	}
 
-	// Filter and sort .sql files
-	var sqlFiles []os.DirEntry
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			sqlFiles = append(sqlFiles, file)
-		}
+	prefix := ""
+	if *dryRun {
+		prefix = "Dry run, nothing changed: "
 	}
-	sort.Slice(sqlFiles, func(i, j int) bool {
-		return sqlFiles[i].Name() < sqlFiles[j].Name()
-	})
-
-	for _, file := range sqlFiles {
-		path := filepath.Join(dir, file.Name())
-
-		content, err := os.ReadFile(path)
-		if err != nil {
-			log.Printf("reading file %s failed: %v", file.Name(), err)
-		}
-
-		sql := string(content)
-		if err := db.Exec(sql).Error; err != nil {
-			log.Printf("executing %s failed: %v", file.Name(), err)
-			return
-		}
-
-		log.Printf("Executed %s successfully\n", file.Name())
+	for _, kind := range []string{fixtures.KindCategories, fixtures.KindProducts, fixtures.KindVariants} {
+		log.Printf("%s%s: %d created, %d updated, %d deleted", prefix, kind,
+			report.Created[kind], report.Updated[kind], report.Deleted[kind])
 	}
 }
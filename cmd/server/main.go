@@ -7,13 +7,22 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/mytheresa/go-hiring-challenge/app/catalog"
 	"github.com/mytheresa/go-hiring-challenge/app/categories"
+	"github.com/mytheresa/go-hiring-challenge/app/imports"
 	"github.com/mytheresa/go-hiring-challenge/app/product"
+	"github.com/mytheresa/go-hiring-challenge/app/reservations"
+	"github.com/mytheresa/go-hiring-challenge/app/sales"
+	"github.com/mytheresa/go-hiring-challenge/app/stock"
+	"github.com/mytheresa/go-hiring-challenge/app/variants"
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/internal/importer"
 	"github.com/mytheresa/go-hiring-challenge/internal/repository"
 )
 
@@ -39,23 +48,71 @@ func main() {
 	// Initialize repositories
 	prodRepo := repository.NewProducts(db)
 	catRepo := repository.NewCategories(db)
+	varRepo := repository.NewVariants(db)
+	stockRepo := repository.NewStock(db)
+	resRepo := repository.NewReservations(db)
+	salesRepo := repository.NewSales(db)
 
 	// Initialize handlers
 	catalogHandler := catalog.NewCatalogHandler(prodRepo)
 	productHandler := product.NewProductHandler(prodRepo)
 	categoriesHandler := categories.NewCategoriesHandler(catRepo)
+	variantsHandler := variants.NewVariantsHandler(varRepo)
+	stockHandler := stock.NewStockHandler(stockRepo)
+	reservationsHandler := reservations.NewReservationsHandler(resRepo)
+	salesHandler := sales.NewSalesHandler(salesRepo)
+	importHandler := imports.NewImportHandler(importer.New(db))
 
 	// Set up routing
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /catalog", catalogHandler.HandleGetAll)
+	mux.HandleFunc("GET /catalog/search", catalogHandler.HandleSearch)
+	mux.HandleFunc("GET /catalog/export", catalogHandler.HandleExport)
+	mux.HandleFunc("POST /catalog", productHandler.HandleCreate)
+	mux.HandleFunc("POST /catalog/import", importHandler.HandleImport)
 	mux.HandleFunc("GET /catalog/{code}", productHandler.HandleGetByCode)
+	mux.HandleFunc("PUT /catalog/{code}", productHandler.HandleUpdate)
+	mux.HandleFunc("PATCH /catalog/{code}", productHandler.HandlePatch)
+	mux.HandleFunc("DELETE /catalog/{code}", productHandler.HandleDelete)
+	mux.HandleFunc("POST /catalog/{code}/restore", productHandler.HandleRestore)
+	mux.HandleFunc("PUT /catalog/{code}/status", productHandler.HandleChangeStatus)
+	mux.HandleFunc("GET /catalog/{code}/price-history", productHandler.HandlePriceHistory)
+	mux.HandleFunc("PUT /catalog/{code}/prices/{currency}", productHandler.HandleSetPrice)
+	mux.HandleFunc("DELETE /catalog/{code}/prices/{currency}", productHandler.HandleDeletePrice)
+	mux.HandleFunc("POST /catalog/{code}/categories", productHandler.HandleAttachCategories)
+	mux.HandleFunc("DELETE /catalog/{code}/categories/{category}", productHandler.HandleDetachCategory)
+	mux.HandleFunc("GET /catalog/{code}/variants", variantsHandler.HandleGetAll)
+	mux.HandleFunc("POST /catalog/{code}/variants", variantsHandler.HandleCreate)
+	mux.HandleFunc("PUT /catalog/{code}/variants/{sku}", variantsHandler.HandleUpdate)
+	mux.HandleFunc("DELETE /catalog/{code}/variants/{sku}", variantsHandler.HandleDelete)
+	mux.HandleFunc("POST /catalog/{code}/variants/{sku}/restore", variantsHandler.HandleRestore)
+	mux.HandleFunc("PUT /catalog/{code}/variants/{sku}/prices/{currency}", variantsHandler.HandleSetPrice)
+	mux.HandleFunc("DELETE /catalog/{code}/variants/{sku}/prices/{currency}", variantsHandler.HandleDeletePrice)
+	mux.HandleFunc("GET /catalog/{code}/sales", salesHandler.HandleGetAll)
+	mux.HandleFunc("POST /catalog/{code}/sales", salesHandler.HandleCreate)
+	mux.HandleFunc("DELETE /catalog/{code}/sales/{id}", salesHandler.HandleDelete)
+	mux.HandleFunc("GET /variants", variantsHandler.HandleGetBySKUs)
+	mux.HandleFunc("GET /variants/{sku}", variantsHandler.HandleGetBySKU)
+	mux.HandleFunc("GET /variants/{sku}/stock", stockHandler.HandleGet)
+	mux.HandleFunc("POST /variants/{sku}/stock/adjustments", stockHandler.HandleAdjust)
+	mux.HandleFunc("PUT /variants/{sku}/stock/{warehouse}", stockHandler.HandleSet)
+	mux.HandleFunc("POST /reservations", reservationsHandler.HandleCreate)
+	mux.HandleFunc("GET /reservations/{id}", reservationsHandler.HandleGet)
+	mux.HandleFunc("POST /reservations/{id}/confirm", reservationsHandler.HandleConfirm)
+	mux.HandleFunc("POST /reservations/{id}/release", reservationsHandler.HandleRelease)
 	mux.HandleFunc("GET /categories", categoriesHandler.HandleGetAll)
 	mux.HandleFunc("POST /categories", categoriesHandler.HandleCreate)
+	mux.HandleFunc("GET /categories/tree", categoriesHandler.HandleGetTree)
+	mux.HandleFunc("GET /categories/{code}", categoriesHandler.HandleGetByCode)
+	mux.HandleFunc("PUT /categories/{code}", categoriesHandler.HandleUpdate)
+	mux.HandleFunc("PATCH /categories/{code}", categoriesHandler.HandlePatch)
+	mux.HandleFunc("DELETE /categories/{code}", categoriesHandler.HandleDelete)
+	mux.HandleFunc("POST /categories/{code}/restore", categoriesHandler.HandleRestore)
 
 	// Set up the HTTP server
 	srv := &http.Server{
 		Addr:    fmt.Sprintf("localhost:%s", os.Getenv("HTTP_PORT")),
-		Handler: mux,
+		Handler: common.AdminScope(os.Getenv("ADMIN_TOKEN"), mux),
 	}
 
 	// Start the server
@@ -68,8 +125,17 @@ func main() {
 		log.Println("Server stopped gracefully")
 	}()
 
+	// Expire stale reservations in the background until shutdown
+	var sweeper sync.WaitGroup
+	sweeper.Add(1)
+	go func() {
+		defer sweeper.Done()
+		reservations.NewSweeper(resRepo, 30*time.Second).Run(ctx)
+	}()
+
 	<-ctx.Done()
 	log.Println("Shutting down server...")
 	srv.Shutdown(ctx)
+	sweeper.Wait()
 	stop()
 }
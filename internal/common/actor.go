@@ -0,0 +1,24 @@
+package common
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AnonymousActor is recorded for changes made without an X-Actor header.
+const AnonymousActor = "anonymous"
+
+// maxActorLength is the longest actor name that is recorded; longer names are cut off.
+const maxActorLength = 128
+
+// ParseActor returns who makes the request, as named by the X-Actor header.
+func ParseActor(r *http.Request) string {
+	actor := strings.TrimSpace(r.Header.Get("X-Actor"))
+	if actor == "" {
+		return AnonymousActor
+	}
+	if len(actor) > maxActorLength {
+		actor = actor[:maxActorLength]
+	}
+	return actor
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// AdminTokenHeader carries the token that gives a request the admin scope.
+const AdminTokenHeader = "X-Admin-Token"
+
+type adminKey struct{}
+
+// AdminScope marks requests carrying the admin token in the X-Admin-Token header as admin
+// requests, which may see unpublished and deleted records. Requests with any other token are
+// refused with 401; requests without the header are served as public ones. An empty token
+// disables the admin scope.
+func AdminScope(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := r.Header[http.CanonicalHeaderKey(AdminTokenHeader)]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token == "" || len(presented) != 1 || subtle.ConstantTimeCompare([]byte(presented[0]), []byte(token)) != 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid admin token"})
+			return
+		}
+		next.ServeHTTP(w, WithAdmin(r))
+	})
+}
+
+// WithAdmin returns the request marked as an admin request.
+func WithAdmin(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), adminKey{}, true))
+}
+
+// IsAdmin reports whether AdminScope accepted the admin token of the request.
+func IsAdmin(r *http.Request) bool {
+	admin, _ := r.Context().Value(adminKey{}).(bool)
+	return admin
+}
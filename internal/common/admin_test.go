@@ -0,0 +1,47 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header []string
+		status int
+		admin  bool
+	}{
+		{"no header is a public request", "secret", nil, http.StatusOK, false},
+		{"the admin token makes an admin request", "secret", []string{"secret"}, http.StatusOK, true},
+		{"a wrong token is refused", "secret", []string{"guess"}, http.StatusUnauthorized, false},
+		{"an empty token is refused", "secret", []string{""}, http.StatusUnauthorized, false},
+		{"repeated headers are refused", "secret", []string{"guess", "secret"}, http.StatusUnauthorized, false},
+		{"no configured token refuses every header", "", []string{""}, http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var admin, served bool
+			handler := AdminScope(tt.token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served, admin = true, IsAdmin(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/catalog?admin=true", nil)
+			for _, value := range tt.header {
+				req.Header.Add(AdminTokenHeader, value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.status == http.StatusOK, served)
+			assert.Equal(t, tt.admin, admin)
+		})
+	}
+}
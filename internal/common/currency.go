@@ -0,0 +1,41 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ParseCurrency reads the requested currency from the currency query parameter, falling back
+// to the Accept-Currency header and then to defaultCurrency. Codes are case-insensitive and
+// must be in allowed.
+func ParseCurrency(r *http.Request, allowed []string, defaultCurrency string) (string, error) {
+	valueStr := r.URL.Query().Get("currency")
+	if valueStr == "" {
+		valueStr = r.Header.Get("Accept-Currency")
+	}
+	if valueStr == "" {
+		return defaultCurrency, nil
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(valueStr))
+	if !slices.Contains(allowed, currency) {
+		return "", fmt.Errorf("unsupported currency %q, supported currencies are %s", valueStr, strings.Join(allowed, ", "))
+	}
+	return currency, nil
+}
+
+// ParsePriceListCurrency reads the currency of a price list from the currency path value. Codes
+// are case-insensitive and must be in allowed; the base currency has no price list, its prices
+// are the prices of the products and variants themselves.
+func ParsePriceListCurrency(r *http.Request, allowed []string, baseCurrency string) (string, error) {
+	currency := strings.ToUpper(r.PathValue("currency"))
+	if currency == baseCurrency {
+		return "", fmt.Errorf("%s prices are set with the price itself", baseCurrency)
+	}
+	if !slices.Contains(allowed, currency) {
+		return "", fmt.Errorf("unsupported currency %q, supported currencies are %s", r.PathValue("currency"), strings.Join(allowed, ", "))
+	}
+	return currency, nil
+}
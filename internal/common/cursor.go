@@ -0,0 +1,60 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or do not match the sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position after the last item of a page in keyset pagination.
+// It holds the values of the sort fields and the id of that item, and the sort
+// order it was created for. Clients only see it as an opaque string.
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     uint     `json:"id"`
+}
+
+// SortKey identifies a sort order, so a cursor is only accepted with the order it was created for.
+func SortKey(sort []SortField) string {
+	keys := make([]string, len(sort))
+	for i, field := range sort {
+		keys[i] = field.String()
+	}
+	return strings.Join(keys, ",")
+}
+
+// Encode returns the opaque string form of the cursor.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor retrieves the cursor query parameter from the HTTP request and checks that it
+// was created for the given sort order. It returns nil when the parameter is missing.
+func ParseCursor(r *http.Request, sort []SortField) (*Cursor, error) {
+	valueStr := r.URL.Query().Get("cursor")
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(valueStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != SortKey(sort) || len(cursor.Values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -3,6 +3,8 @@ package common
 import (
 	"net/http"
 	"strconv"
+
+	"github.com/shopspring/decimal"
 )
 
 // ParseIntParam retrieves an integer query parameter from the HTTP request.
@@ -35,3 +37,35 @@ func ParseOffsetLimit(r *http.Request) (int, int) {
 
 	return offset, limit
 }
+
+// ParseDecimalParam retrieves a decimal query parameter from the HTTP request.
+// It returns nil when the parameter is missing or not a valid decimal.
+func ParseDecimalParam(r *http.Request, param string) *decimal.Decimal {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return nil
+	}
+
+	value, err := decimal.NewFromString(valueStr)
+	if err != nil {
+		return nil
+	}
+
+	return &value
+}
+
+// ParseBoolParam retrieves a boolean query parameter from the HTTP request.
+// It returns nil when the parameter is missing or not a valid boolean.
+func ParseBoolParam(r *http.Request, param string) *bool {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// SortField is one key of a sort order. In the sort query parameter a leading "-"
+// sorts the field in descending order, e.g. sort=-price,code.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// String returns the field as written in the sort query parameter.
+func (f SortField) String() string {
+	if f.Desc {
+		return "-" + f.Name
+	}
+	return f.Name
+}
+
+// ParseSort parses the comma separated sort query parameter. Every field must be in allowed
+// and may only appear once. It returns nil when the parameter is missing.
+func ParseSort(r *http.Request, allowed []string) ([]SortField, error) {
+	valueStr := r.URL.Query().Get("sort")
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(valueStr, ",") {
+		field := SortField{Name: strings.TrimSpace(part)}
+		if name, ok := strings.CutPrefix(field.Name, "-"); ok {
+			field = SortField{Name: name, Desc: true}
+		}
+
+		if !slices.Contains(allowed, field.Name) {
+			return nil, fmt.Errorf("invalid sort field %q, allowed fields are %s", field.Name, strings.Join(allowed, ", "))
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate sort field %q", field.Name)
+		}
+		seen[field.Name] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 
@@ -21,13 +22,16 @@ type Database interface {
 	Preload(query string, args ...interface{}) *gorm.DB
 	Count(count *int64) *gorm.DB
 	Exec(query string, args ...interface{}) *gorm.DB
+	Raw(query string, args ...interface{}) *gorm.DB
 	Model(value interface{}) *gorm.DB
+	Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error
+	Connection(fc func(tx *gorm.DB) error) error
 }
 
 func New(user, password, dbname, port string) (Database, func() error) {
 	dsn := fmt.Sprintf("postgres://%s:%s@localhost:%s/%s?sslmode=disable", user, password, port, dbname)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("failed to connect database: %s", err)
 	}
@@ -87,6 +91,19 @@ func (g *GormDB) Count(count *int64) *gorm.DB {
 func (g *GormDB) Exec(query string, args ...interface{}) *gorm.DB {
 	return g.DB.Exec(query, args...)
 }
+
+func (g *GormDB) Raw(query string, args ...interface{}) *gorm.DB {
+	return g.DB.Raw(query, args...)
+}
+
 func (g *GormDB) Model(value interface{}) *gorm.DB {
 	return g.DB.Model(value)
 }
+
+func (g *GormDB) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	return g.DB.Transaction(fc, opts...)
+}
+
+func (g *GormDB) Connection(fc func(tx *gorm.DB) error) error {
+	return g.DB.Connection(fc)
+}
@@ -0,0 +1,191 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of fixtures, in the order they are loaded.
+const (
+	KindCategories = "categories"
+	KindProducts   = "products"
+	// KindVariants are seeded together with their products; Report counts them apart.
+	KindVariants = "variants"
+)
+
+// Kinds lists the fixture kinds; products reference categories, so categories come first.
+var Kinds = []string{KindCategories, KindProducts}
+
+// Fixtures is sample data, upserted by category code, product code and SKU.
+type Fixtures struct {
+	Categories []Category `json:"categories" yaml:"categories"`
+	Products   []Product  `json:"products" yaml:"products"`
+}
+
+// Category is a category fixture. Parent is the code of a category listed before it or already stored.
+type Category struct {
+	Code   string  `json:"code" yaml:"code"`
+	Name   string  `json:"name" yaml:"name"`
+	Parent *string `json:"parent" yaml:"parent"`
+}
+
+// Product is a product fixture with its variants. Category is the primary category; Categories
+// lists further assignments. Prices holds the price lists in currencies other than EUR.
+// PublishIn and UnpublishIn schedule the publication relative to the time of seeding.
+type Product struct {
+	Code        string                     `json:"code" yaml:"code"`
+	Name        string                     `json:"name" yaml:"name"`
+	Description string                     `json:"description" yaml:"description"`
+	Brand       string                     `json:"brand" yaml:"brand"`
+	Price       decimal.Decimal            `json:"price" yaml:"price"`
+	Status      string                     `json:"status" yaml:"status"`
+	PublishIn   *Offset                    `json:"publishIn" yaml:"publishIn"`
+	UnpublishIn *Offset                    `json:"unpublishIn" yaml:"unpublishIn"`
+	Category    *string                    `json:"category" yaml:"category"`
+	Categories  []string                   `json:"categories" yaml:"categories"`
+	Images      []Image                    `json:"images" yaml:"images"`
+	Options     []string                   `json:"options" yaml:"options"`
+	Prices      map[string]decimal.Decimal `json:"prices" yaml:"prices"`
+	Sales       []Sale                     `json:"sales" yaml:"sales"`
+	Variants    []Variant                  `json:"variants" yaml:"variants"`
+}
+
+// Image is a product image; images are shown in the order they are listed.
+type Image struct {
+	URL     string `json:"url" yaml:"url"`
+	AltText string `json:"altText" yaml:"altText"`
+}
+
+// Variant is a variant fixture. Without a price it inherits the product price; Stock holds the
+// quantity on hand per warehouse.
+type Variant struct {
+	SKU     string                     `json:"sku" yaml:"sku"`
+	Name    string                     `json:"name" yaml:"name"`
+	Price   *decimal.Decimal           `json:"price" yaml:"price"`
+	Options map[string]string          `json:"options" yaml:"options"`
+	Prices  map[string]decimal.Decimal `json:"prices" yaml:"prices"`
+	Sales   []Sale                     `json:"sales" yaml:"sales"`
+	Stock   map[string]int             `json:"stock" yaml:"stock"`
+}
+
+// Sale is a sale price fixture, running from StartsIn until EndsIn after the time of seeding.
+// Currency defaults to EUR.
+type Sale struct {
+	Currency string          `json:"currency" yaml:"currency"`
+	Price    decimal.Decimal `json:"price" yaml:"price"`
+	StartsIn Offset          `json:"startsIn" yaml:"startsIn"`
+	EndsIn   Offset          `json:"endsIn" yaml:"endsIn"`
+}
+
+// Offset is a time relative to seeding, written as a duration such as "24h" or "-30m", so
+// sample schedules stay in the near future however old the fixtures are.
+type Offset time.Duration
+
+// UnmarshalText reads the offset from a duration string.
+func (o *Offset) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*o = Offset(duration)
+	return nil
+}
+
+// From returns the time the offset points to, counted from now.
+func (o Offset) From(now time.Time) time.Time {
+	return now.Add(time.Duration(o))
+}
+
+// Load reads the .json, .yaml and .yml files of a directory in name order and merges them.
+// Unknown fields and codes or SKUs defined twice are errors.
+func Load(dir fs.FS) (*Fixtures, error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &Fixtures{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		var decode func([]byte, *Fixtures) error
+		switch path.Ext(entry.Name()) {
+		case ".json":
+			decode = decodeJSON
+		case ".yaml", ".yml":
+			decode = decodeYAML
+		default:
+			continue
+		}
+
+		content, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		var file Fixtures
+		if err := decode(content, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		merged.Categories = append(merged.Categories, file.Categories...)
+		merged.Products = append(merged.Products, file.Products...)
+	}
+
+	if err := merged.checkUnique(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func decodeJSON(content []byte, fixtures *Fixtures) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(fixtures)
+}
+
+func decodeYAML(content []byte, fixtures *Fixtures) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(fixtures); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// checkUnique rejects category codes, product codes and SKUs defined more than once.
+func (f *Fixtures) checkUnique() error {
+	categories := make(map[string]bool, len(f.Categories))
+	for _, category := range f.Categories {
+		if categories[category.Code] {
+			return fmt.Errorf("category %s is defined twice", category.Code)
+		}
+		categories[category.Code] = true
+	}
+
+	products := make(map[string]bool, len(f.Products))
+	skus := make(map[string]bool)
+	for _, product := range f.Products {
+		if products[product.Code] {
+			return fmt.Errorf("product %s is defined twice", product.Code)
+		}
+		products[product.Code] = true
+
+		for _, variant := range product.Variants {
+			if skus[variant.SKU] {
+				return fmt.Errorf("variant %s is defined twice", variant.SKU)
+			}
+			skus[variant.SKU] = true
+		}
+	}
+	return nil
+}
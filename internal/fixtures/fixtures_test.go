@@ -0,0 +1,176 @@
+package fixtures
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("merges json and yaml files in name order", func(t *testing.T) {
+		dir := fstest.MapFS{
+			"categories.yaml": {Data: []byte("categories:\n  - code: SHOES\n    name: Shoes\n  - code: BOOTS\n    name: Boots\n    parent: SHOES\n")},
+			"products.json": {Data: []byte(`{"products": [{"code": "PROD001", "price": "10.99", "publishIn": "24h",
+				"sales": [{"price": "8.99", "startsIn": "-1h", "endsIn": "720h"}],
+				"variants": [{"sku": "SKU001A", "price": 11.5, "options": {"Size": "M"}, "stock": {"default": 3}}]}]}`)},
+			"README.md": {Data: []byte("not a fixture")},
+			"more.yml":  {Data: []byte("categories:\n  - code: SALE\n    name: Sale\n")},
+		}
+
+		fixtures, err := Load(dir)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"SHOES", "BOOTS", "SALE"}, codes(fixtures.Categories))
+		assert.Equal(t, "SHOES", *fixtures.Categories[1].Parent)
+		assert.Len(t, fixtures.Products, 1)
+
+		product := fixtures.Products[0]
+		assert.True(t, product.Price.Equal(decimal.RequireFromString("10.99")))
+		assert.Equal(t, Offset(24*time.Hour), *product.PublishIn)
+		assert.Nil(t, product.UnpublishIn)
+		assert.Equal(t, Offset(-time.Hour), product.Sales[0].StartsIn)
+		assert.True(t, product.Variants[0].Price.Equal(decimal.RequireFromString("11.5")))
+		assert.Equal(t, map[string]string{"Size": "M"}, product.Variants[0].Options)
+		assert.Equal(t, map[string]int{"default": 3}, product.Variants[0].Stock)
+	})
+
+	t.Run("loads the bundled fixtures", func(t *testing.T) {
+		fixtures, err := Load(os.DirFS("."))
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, fixtures.Categories)
+		assert.NotEmpty(t, fixtures.Products)
+	})
+
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"unknown json field", fstest.MapFS{"p.json": {Data: []byte(`{"products": [{"code": "PROD001", "colour": "red"}]}`)}}},
+		{"unknown yaml field", fstest.MapFS{"c.yaml": {Data: []byte("categories:\n  - code: SHOES\n    title: Shoes\n")}}},
+		{"malformed json", fstest.MapFS{"p.json": {Data: []byte(`{"products": [`)}}},
+		{"bad decimal", fstest.MapFS{"p.json": {Data: []byte(`{"products": [{"code": "PROD001", "price": "abc"}]}`)}}},
+		{"bad offset", fstest.MapFS{"p.json": {Data: []byte(`{"products": [{"code": "PROD001", "publishIn": "tomorrow"}]}`)}}},
+		{"category defined in two files", fstest.MapFS{
+			"a.yaml": {Data: []byte("categories:\n  - code: SHOES\n")},
+			"b.json": {Data: []byte(`{"categories": [{"code": "SHOES"}]}`)},
+		}},
+	}
+	for _, test := range tests {
+		t.Run("rejects "+test.name, func(t *testing.T) {
+			_, err := Load(test.files)
+
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("accepts an empty yaml file", func(t *testing.T) {
+		fixtures, err := Load(fstest.MapFS{"empty.yaml": {Data: []byte("")}})
+
+		assert.NoError(t, err)
+		assert.Empty(t, fixtures.Products)
+	})
+}
+
+func TestCheckUnique(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures Fixtures
+		err      string
+	}{
+		{
+			name: "distinct codes and skus",
+			fixtures: Fixtures{
+				Categories: []Category{{Code: "SHOES"}, {Code: "BOOTS"}},
+				Products: []Product{
+					{Code: "PROD001", Variants: []Variant{{SKU: "SKU001A"}, {SKU: "SKU001B"}}},
+					{Code: "PROD002", Variants: []Variant{{SKU: "SKU002A"}}},
+				},
+			},
+		},
+		{
+			name:     "duplicate category",
+			fixtures: Fixtures{Categories: []Category{{Code: "SHOES"}, {Code: "SHOES"}}},
+			err:      "category SHOES is defined twice",
+		},
+		{
+			name:     "duplicate product",
+			fixtures: Fixtures{Products: []Product{{Code: "PROD001"}, {Code: "PROD001"}}},
+			err:      "product PROD001 is defined twice",
+		},
+		{
+			name: "sku shared by two products",
+			fixtures: Fixtures{Products: []Product{
+				{Code: "PROD001", Variants: []Variant{{SKU: "SKU001A"}}},
+				{Code: "PROD002", Variants: []Variant{{SKU: "SKU001A"}}},
+			}},
+			err: "variant SKU001A is defined twice",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.fixtures.checkUnique()
+
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestParentsFirst(t *testing.T) {
+	parent := func(code string) *string { return &code }
+
+	tests := []struct {
+		name       string
+		categories []Category
+		expected   []string
+	}{
+		{
+			name:       "keeps an ordered list",
+			categories: []Category{{Code: "SHOES"}, {Code: "BOOTS", Parent: parent("SHOES")}},
+			expected:   []string{"SHOES", "BOOTS"},
+		},
+		{
+			name: "moves parents before their children",
+			categories: []Category{
+				{Code: "ANKLE", Parent: parent("BOOTS")},
+				{Code: "BOOTS", Parent: parent("SHOES")},
+				{Code: "SALE"},
+				{Code: "SHOES"},
+			},
+			expected: []string{"SHOES", "BOOTS", "ANKLE", "SALE"},
+		},
+		{
+			name:       "keeps children of stored parents in place",
+			categories: []Category{{Code: "BOOTS", Parent: parent("SHOES")}, {Code: "SALE"}},
+			expected:   []string{"BOOTS", "SALE"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, codes(parentsFirst(test.categories)))
+		})
+	}
+}
+
+func TestOffset(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(24*time.Hour), Offset(24*time.Hour).From(now))
+	assert.Equal(t, now.Add(-30*time.Minute), Offset(-30*time.Minute).From(now))
+}
+
+func codes(categories []Category) []string {
+	result := make([]string, len(categories))
+	for i, category := range categories {
+		result[i] = category.Code
+	}
+	return result
+}
@@ -0,0 +1,407 @@
+package fixtures
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Actor is recorded as the author of the price changes fixtures make.
+const Actor = "seed"
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// Options select what Seed loads and how.
+type Options struct {
+	// Only limits seeding to the given kinds; empty means all of them.
+	Only []string
+	// DryRun runs everything and rolls it back, reporting what would have changed.
+	DryRun bool
+	// Reset deletes the records the fixtures define before loading them. Records the
+	// fixtures do not mention are left alone.
+	Reset bool
+}
+
+// Report counts the records seeding created, updated and deleted, per kind.
+type Report struct {
+	Created map[string]int
+	Updated map[string]int
+	Deleted map[string]int
+}
+
+// Seed upserts the fixtures through the repositories in a single transaction, so a failing
+// fixture leaves the database unchanged. Seeding the same fixtures twice changes nothing the
+// second time, except that publication schedules and sales move along with the time of seeding.
+func Seed(db database.Database, fixtures *Fixtures, options Options, logger *log.Logger) (*Report, error) {
+	for _, kind := range options.Only {
+		if !slices.Contains(Kinds, kind) {
+			return nil, fmt.Errorf("unknown fixture kind %q", kind)
+		}
+	}
+
+	s := &seeder{
+		now:      time.Now(),
+		fixtures: fixtures,
+		logger:   logger,
+		report: &Report{
+			Created: make(map[string]int),
+			Updated: make(map[string]int),
+			Deleted: make(map[string]int),
+		},
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		s.tx = tx
+		if options.Reset {
+			// Products reference categories, so they are deleted first
+			if s.selected(options, KindProducts) {
+				if err := s.resetProducts(); err != nil {
+					return err
+				}
+			}
+			if s.selected(options, KindCategories) {
+				if err := s.resetCategories(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if s.selected(options, KindCategories) {
+			if err := s.seedCategories(); err != nil {
+				return err
+			}
+		}
+		if s.selected(options, KindProducts) {
+			if err := s.seedProducts(); err != nil {
+				return err
+			}
+		}
+
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return s.report, nil
+}
+
+type seeder struct {
+	tx       *gorm.DB
+	now      time.Time
+	fixtures *Fixtures
+	logger   *log.Logger
+	report   *Report
+}
+
+func (s *seeder) selected(options Options, kind string) bool {
+	return len(options.Only) == 0 || slices.Contains(options.Only, kind)
+}
+
+// resetProducts deletes the fixture products for good, including deleted ones with the same
+// code, and through the foreign keys their variants, stock and price history.
+func (s *seeder) resetProducts() error {
+	codes := make([]string, len(s.fixtures.Products))
+	for i, product := range s.fixtures.Products {
+		codes[i] = product.Code
+	}
+
+	result := s.tx.Unscoped().Where("code IN ?", codes).Delete(&models.Product{})
+	if result.Error != nil {
+		return result.Error
+	}
+	s.report.Deleted[KindProducts] += int(result.RowsAffected)
+	return nil
+}
+
+// resetCategories deletes the fixture categories for good. It fails while products that are
+// not fixtures still use them.
+func (s *seeder) resetCategories() error {
+	codes := make([]string, len(s.fixtures.Categories))
+	for i, category := range s.fixtures.Categories {
+		codes[i] = category.Code
+	}
+
+	result := s.tx.Unscoped().Where("code IN ?", codes).Delete(&models.Category{})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return fmt.Errorf("resetting categories: %w", repository.ErrInUse)
+		}
+		return result.Error
+	}
+	s.report.Deleted[KindCategories] += int(result.RowsAffected)
+	return nil
+}
+
+// seedCategories upserts the categories, parents before their children.
+func (s *seeder) seedCategories() error {
+	repo := repository.NewCategories(s.tx)
+	for _, fixture := range parentsFirst(s.fixtures.Categories) {
+		category, err := repo.GetCategoryByCode(fixture.Code)
+		created := errors.Is(err, repository.ErrNotFound)
+		if created {
+			category, err = &models.Category{Code: fixture.Code}, nil
+		}
+		if err != nil {
+			return fmt.Errorf("category %s: %w", fixture.Code, err)
+		}
+
+		category.Name = fixture.Name
+		category.Parent = nil
+		if fixture.Parent != nil && *fixture.Parent != "" {
+			category.Parent = &models.Category{Code: *fixture.Parent}
+		}
+
+		if created {
+			err = repo.CreateCategory(category)
+		} else {
+			err = repo.UpdateCategory(category)
+		}
+		if err != nil {
+			return fmt.Errorf("category %s: %w", fixture.Code, err)
+		}
+		s.counted(KindCategories, fixture.Code, created)
+	}
+	return nil
+}
+
+// seedProducts upserts the products with their category assignments, price lists and variants.
+func (s *seeder) seedProducts() error {
+	repo := repository.NewProducts(s.tx)
+	for _, fixture := range s.fixtures.Products {
+		product, err := repo.GetProductByCode(fixture.Code, repository.AllProducts)
+		created := errors.Is(err, repository.ErrNotFound)
+		if created {
+			product, err = &models.Product{Code: fixture.Code}, nil
+		}
+		if err != nil {
+			return fmt.Errorf("product %s: %w", fixture.Code, err)
+		}
+
+		product.Name = fixture.Name
+		product.Description = fixture.Description
+		product.Brand = fixture.Brand
+		product.Price = fixture.Price
+		product.Status = fixture.Status
+		if product.Status == "" {
+			product.Status = models.StatusDraft
+		}
+		if !slices.Contains(models.ProductStatuses, product.Status) {
+			return fmt.Errorf("product %s: unknown status %q", fixture.Code, product.Status)
+		}
+		product.PublishAt, product.UnpublishAt = nil, nil
+		if fixture.PublishIn != nil {
+			publishAt := fixture.PublishIn.From(s.now)
+			product.PublishAt = &publishAt
+		}
+		if fixture.UnpublishIn != nil {
+			unpublishAt := fixture.UnpublishIn.From(s.now)
+			product.UnpublishAt = &unpublishAt
+		}
+		product.Category = nil
+		if fixture.Category != nil && *fixture.Category != "" {
+			product.Category = &models.Category{Code: *fixture.Category}
+		}
+		product.Images = make([]models.ProductImage, len(fixture.Images))
+		for i, image := range fixture.Images {
+			product.Images[i] = models.ProductImage{URL: image.URL, AltText: image.AltText}
+		}
+		product.Options = make([]models.ProductOption, len(fixture.Options))
+		for i, name := range fixture.Options {
+			product.Options[i] = models.ProductOption{Name: name}
+		}
+
+		if created {
+			err = repo.CreateProduct(product, Actor)
+		} else {
+			err = repo.UpdateProduct(product, Actor)
+		}
+		if err == nil && len(fixture.Categories) > 0 {
+			err = repo.AttachCategories(fixture.Code, fixture.Categories)
+		}
+		if err == nil {
+			err = s.setProductPrices(fixture.Code, fixture.Prices)
+		}
+		if err != nil {
+			return fmt.Errorf("product %s: %w", fixture.Code, err)
+		}
+		s.counted(KindProducts, fixture.Code, created)
+
+		for _, variant := range fixture.Variants {
+			if err := s.seedVariant(fixture.Code, variant); err != nil {
+				return fmt.Errorf("variant %s: %w", variant.SKU, err)
+			}
+		}
+
+		// Variants exist by now, so sales of the product and of its variants can be scheduled
+		if err := s.replaceSales(fixture.Code, "", "product_id", product.ID, fixture.Sales); err != nil {
+			return fmt.Errorf("product %s: %w", fixture.Code, err)
+		}
+	}
+	return nil
+}
+
+// seedVariant upserts a variant of a product with its price lists and stock.
+func (s *seeder) seedVariant(productCode string, fixture Variant) error {
+	repo := repository.NewVariants(s.tx)
+	variant, err := repo.GetVariant(productCode, fixture.SKU)
+	created := errors.Is(err, repository.ErrNotFound)
+	if created {
+		variant, err = &models.Variant{SKU: fixture.SKU}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	variant.Name = fixture.Name
+	variant.Price = decimal.NullDecimal{}
+	if fixture.Price != nil {
+		variant.Price = decimal.NewNullDecimal(*fixture.Price)
+	}
+	variant.Options = make([]models.VariantOption, 0, len(fixture.Options))
+	for name, value := range fixture.Options {
+		variant.Options = append(variant.Options, models.VariantOption{Name: name, Value: value})
+	}
+
+	if created {
+		err = repo.CreateVariant(productCode, variant, Actor)
+	} else {
+		err = repo.UpdateVariant(variant, Actor)
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.setVariantPrices(productCode, fixture.SKU, fixture.Prices); err != nil {
+		return err
+	}
+	if err := s.replaceSales(productCode, fixture.SKU, "variant_id", variant.ID, fixture.Sales); err != nil {
+		return err
+	}
+
+	stock := repository.NewStock(s.tx)
+	warehouses := make([]string, 0, len(fixture.Stock))
+	for warehouse := range fixture.Stock {
+		warehouses = append(warehouses, warehouse)
+	}
+	sort.Strings(warehouses)
+	for _, warehouse := range warehouses {
+		if _, err := stock.SetStock(fixture.SKU, warehouse, fixture.Stock[warehouse]); err != nil {
+			return err
+		}
+	}
+
+	s.counted(KindVariants, fixture.SKU, created)
+	return nil
+}
+
+// setProductPrices stores the price list entries of a product, keeping other currencies.
+func (s *seeder) setProductPrices(code string, prices map[string]decimal.Decimal) error {
+	repo := repository.NewProducts(s.tx)
+	for _, currency := range sortedCurrencies(prices) {
+		if _, err := repo.SetProductPrice(code, currency, prices[currency], Actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setVariantPrices stores the price list entries of a variant, keeping other currencies.
+func (s *seeder) setVariantPrices(productCode, sku string, prices map[string]decimal.Decimal) error {
+	repo := repository.NewVariants(s.tx)
+	for _, currency := range sortedCurrencies(prices) {
+		if _, err := repo.SetVariantPrice(productCode, sku, currency, prices[currency], Actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSales swaps the sales of a product, or of its variant when sku is set, for the fixture
+// sales. Without sales in the fixture the stored ones are left alone.
+func (s *seeder) replaceSales(productCode, sku, column string, id uint, sales []Sale) error {
+	if sales == nil {
+		return nil
+	}
+	if err := s.tx.Where(column+" = ?", id).Delete(&models.SalePrice{}).Error; err != nil {
+		return err
+	}
+
+	repo := repository.NewSales(s.tx)
+	for _, fixture := range sales {
+		sale := &models.SalePrice{
+			SKU:      sku,
+			Currency: fixture.Currency,
+			Price:    fixture.Price,
+			StartsAt: fixture.StartsIn.From(s.now),
+			EndsAt:   fixture.EndsIn.From(s.now),
+		}
+		if sale.Currency == "" {
+			sale.Currency = models.BaseCurrency
+		}
+		if err := repo.CreateSale(productCode, sale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// counted reports an upserted record and adds it to the report.
+func (s *seeder) counted(kind, key string, created bool) {
+	if created {
+		s.report.Created[kind]++
+		s.logger.Printf("Created %s %s", kind, key)
+	} else {
+		s.report.Updated[kind]++
+		s.logger.Printf("Updated %s %s", kind, key)
+	}
+}
+
+// sortedCurrencies returns the currencies of a price list in a stable order.
+func sortedCurrencies(prices map[string]decimal.Decimal) []string {
+	currencies := make([]string, 0, len(prices))
+	for currency := range prices {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// parentsFirst orders categories so every parent in the list comes before its children.
+// Parents that are not in the list must already be stored.
+func parentsFirst(categories []Category) []Category {
+	byCode := make(map[string]Category, len(categories))
+	for _, category := range categories {
+		byCode[category.Code] = category
+	}
+
+	ordered := make([]Category, 0, len(categories))
+	visited := make(map[string]bool, len(categories))
+	var visit func(category Category)
+	visit = func(category Category) {
+		if visited[category.Code] {
+			return
+		}
+		visited[category.Code] = true
+		if category.Parent != nil {
+			if parent, ok := byCode[*category.Parent]; ok {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, category)
+	}
+	for _, category := range categories {
+		visit(category)
+	}
+	return ordered
+}
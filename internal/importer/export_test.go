@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/app/catalog"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// exportRepository serves the catalog export from fixed rows; other methods are not used.
+type exportRepository struct {
+	repository.ProductsInterface
+	rows []repository.ExportRow
+}
+
+func (r *exportRepository) GetProductOptionNames(filter repository.ProductsFilter) ([]string, error) {
+	return []string{"Size", "Color"}, nil
+}
+
+func (r *exportRepository) ExportProducts(filter repository.ProductsFilter, fn func(repository.ExportRow) error) error {
+	for _, row := range r.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestImportExport(t *testing.T) {
+	shirt := repository.ExportRow{
+		Code:        "PROD001",
+		Name:        "Shirt",
+		Description: `Cotton, "slim" fit`,
+		Brand:       "Acme",
+		Price:       decimal.RequireFromString("10.99"),
+		Category:    "CLOTHING",
+		Categories:  []string{"SALE", "SHIRTS"},
+		Status:      models.StatusPublished,
+	}
+	small, large := shirt, shirt
+	small.SKU, small.VariantName = "SKU001A", "S / Black"
+	small.Options = []models.VariantOption{{Name: "Size", Value: "S"}, {Name: "Color", Value: "Black"}}
+	large.SKU, large.VariantName = "SKU001B", "L / Black"
+	large.VariantPrice = decimal.NewNullDecimal(decimal.RequireFromString("12.50"))
+	large.Options = []models.VariantOption{{Name: "Size", Value: "L"}, {Name: "Color", Value: "Black"}}
+	archived := repository.ExportRow{Code: "PROD002", Name: "Bag", Price: decimal.RequireFromString("99"), Status: models.StatusArchived}
+	exported := []repository.ExportRow{small, large, archived}
+
+	for _, format := range []string{FormatCSV, FormatNDJSON} {
+		t.Run("imports a "+format+" export", func(t *testing.T) {
+			handler := catalog.NewCatalogHandler(&exportRepository{rows: exported})
+			req := httptest.NewRequest(http.MethodGet, "/catalog/export?format="+format+"&admin=true", nil)
+			rec := httptest.NewRecorder()
+			handler.HandleExport(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			rows, err := Parse(rec.Body, format)
+			assert.NoError(t, err)
+			validateRows(rows, group(rows))
+
+			assert.Len(t, rows, len(exported))
+			for n, row := range rows {
+				source := exported[n]
+				assert.Empty(t, row.Errors, "row %d", n)
+				assert.Equal(t, source.Code, row.Code)
+				assert.Equal(t, source.Name, *row.Name)
+				assert.True(t, row.Price.Equal(source.Price))
+				assert.Equal(t, source.Categories, row.Categories)
+				assert.Equal(t, source.Status, *row.Status)
+				assert.Equal(t, source.SKU, row.SKU)
+				assert.Equal(t, source.VariantName, row.VariantName)
+				assert.Equal(t, source.VariantPrice.Valid, row.VariantPrice != nil)
+				assert.Len(t, row.Options, len(source.Options))
+				for i, option := range source.Options {
+					assert.Equal(t, Option{Name: option.Name, Value: option.Value}, row.Options[i])
+				}
+			}
+			assert.Equal(t, `Cotton, "slim" fit`, *rows[0].Description)
+			assert.Equal(t, "CLOTHING", *rows[0].Category)
+			assert.True(t, rows[1].VariantPrice.Equal(decimal.RequireFromString("12.50")))
+		})
+	}
+}
@@ -0,0 +1,538 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/internal/repository"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Row statuses in a Report.
+const (
+	// StatusCreated and StatusUpdated tell what happened, or in a dry run would happen, to the
+	// variant of the row, or to the product of a row without SKU.
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	// StatusInvalid rows failed validation.
+	StatusInvalid = "invalid"
+	// StatusFailed rows were valid but their batch was rolled back.
+	StatusFailed = "failed"
+	// StatusSkipped rows were valid but not imported because other rows are invalid.
+	StatusSkipped = "skipped"
+)
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+var validate = validator.New()
+
+type ImporterInterface interface {
+	Import(rows []Row, options Options) (*Report, error)
+}
+
+// Options select how rows are imported.
+type Options struct {
+	// DryRun validates and imports everything, then rolls it back.
+	DryRun bool
+	// BatchSize is the number of products imported per transaction; 0 imports all of them in one,
+	// so any failure leaves the catalog unchanged.
+	BatchSize int
+	// Actor is recorded as the author of the price changes.
+	Actor string
+}
+
+// Report tells what happened to every row of an import.
+type Report struct {
+	DryRun bool        `json:"dryRun"`
+	Rows   []RowReport `json:"rows"`
+	// Created, Updated, Invalid, Failed and Skipped count the rows by status.
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Invalid int `json:"invalid"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// RowReport is the outcome of a row, with the errors that kept it from being imported.
+type RowReport struct {
+	Line   int      `json:"line"`
+	Code   string   `json:"code"`
+	SKU    string   `json:"sku,omitempty"`
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Succeeded reports whether every row was imported, or in a dry run would be.
+func (r *Report) Succeeded() bool {
+	return r.Invalid == 0 && r.Failed == 0 && r.Skipped == 0
+}
+
+type Importer struct {
+	db database.Database
+}
+
+func New(db database.Database) *Importer {
+	return &Importer{
+		db: db,
+	}
+}
+
+// Import validates all rows and, if every row is valid, upserts their products by code and
+// variants by SKU. Product fields are taken from the first row of the product that sets them;
+// fields no row sets keep their stored value, as do the variant name, price and options a row
+// leaves out. Further categories are assigned in addition to the stored ones. Images, price lists
+// and variants not in the rows are kept; the status only applies to new products.
+func (i *Importer) Import(rows []Row, options Options) (*Report, error) {
+	report := &Report{DryRun: options.DryRun, Rows: make([]RowReport, len(rows))}
+	for n, row := range rows {
+		report.Rows[n] = RowReport{Line: row.Line, Code: row.Code, SKU: row.SKU}
+	}
+
+	products := group(rows)
+	skus := validateRows(rows, products)
+	if err := i.validateStored(rows, products, skus); err != nil {
+		return nil, err
+	}
+	if !valid(rows) {
+		report.skip(rows)
+		report.count()
+		return report, nil
+	}
+
+	for _, batch := range batches(products, options.BatchSize) {
+		err := i.db.Transaction(func(tx *gorm.DB) error {
+			for _, product := range batch {
+				if err := upsert(tx, product, rows, options.Actor, report); err != nil {
+					return fmt.Errorf("product %s: %w", product.code, err)
+				}
+			}
+			if options.DryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			report.fail(batch, err)
+		}
+	}
+
+	report.count()
+	return report, nil
+}
+
+// valid reports whether no row has errors.
+func valid(rows []Row) bool {
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// batches splits the products into batches of size products; a size of 0 or less is one batch.
+func batches(products []*product, size int) [][]*product {
+	if size <= 0 {
+		size = len(products)
+	}
+	var result [][]*product
+	for start := 0; start < len(products); start += size {
+		result = append(result, products[start:min(start+size, len(products))])
+	}
+	return result
+}
+
+// product is the rows of one product code, as indexes into the imported rows.
+type product struct {
+	code string
+	rows []int
+	// fields holds the product fields, merged from the rows that set them.
+	fields Row
+	// options are the option names of the variant rows, in the order they first appear.
+	options []string
+}
+
+// group collects the rows of each product, in the order the products first appear.
+func group(rows []Row) []*product {
+	var products []*product
+	byCode := make(map[string]*product)
+	for n, row := range rows {
+		if row.Code == "" {
+			continue
+		}
+		p, ok := byCode[row.Code]
+		if !ok {
+			p = &product{code: row.Code, fields: Row{Code: row.Code}}
+			byCode[row.Code] = p
+			products = append(products, p)
+		}
+		p.rows = append(p.rows, n)
+	}
+	return products
+}
+
+// validateRows records the errors of every row that need no lookup: field rules, bad prices,
+// fields that contradict an earlier row of the product and duplicate SKUs. It returns the line
+// of every SKU.
+func validateRows(rows []Row, products []*product) map[string]int {
+	skus := make(map[string]int)
+	for n := range rows {
+		row := &rows[n]
+		if err := validate.Struct(row); err != nil {
+			row.Errors = append(row.Errors, "Validation error: "+err.Error())
+		}
+		if row.Price != nil && row.Price.IsNegative() {
+			row.Errors = append(row.Errors, "price must not be negative")
+		}
+		if row.VariantPrice != nil && row.VariantPrice.IsNegative() {
+			row.Errors = append(row.Errors, "variantPrice must not be negative")
+		}
+
+		if row.SKU == "" {
+			if row.VariantName != "" || row.VariantPrice != nil || len(row.Options) > 0 {
+				row.Errors = append(row.Errors, "variant fields need a sku")
+			}
+			continue
+		}
+		if line, ok := skus[row.SKU]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("sku %s is already used on line %d", row.SKU, line))
+		} else {
+			skus[row.SKU] = row.Line
+		}
+		if row.VariantName == "" && len(row.Options) == 0 {
+			row.Errors = append(row.Errors, "variantName is required for a variant without options")
+		}
+	}
+
+	for _, p := range products {
+		p.merge(rows)
+	}
+	return skus
+}
+
+// merge fills the product fields from its rows, recording fields that contradict an earlier
+// row and variant rows whose option names differ from the first variant row.
+func (p *product) merge(rows []Row) {
+	fields := &p.fields
+	optionsLine := 0
+	for _, n := range p.rows {
+		row := &rows[n]
+		for _, field := range []struct {
+			name   string
+			value  *string
+			merged **string
+		}{
+			{"name", row.Name, &fields.Name},
+			{"description", row.Description, &fields.Description},
+			{"brand", row.Brand, &fields.Brand},
+			{"category", row.Category, &fields.Category},
+			{"status", row.Status, &fields.Status},
+		} {
+			switch {
+			case field.value == nil:
+			case *field.merged == nil:
+				*field.merged = field.value
+			case **field.merged != *field.value:
+				row.Errors = append(row.Errors, fmt.Sprintf("%s differs from an earlier row of product %s", field.name, p.code))
+			}
+		}
+		switch {
+		case row.Price == nil:
+		case fields.Price == nil:
+			fields.Price = row.Price
+		case !fields.Price.Equal(*row.Price):
+			row.Errors = append(row.Errors, fmt.Sprintf("price differs from an earlier row of product %s", p.code))
+		}
+		switch {
+		case row.Categories == nil:
+		case fields.Categories == nil:
+			fields.Categories = row.Categories
+		case !sameCodes(fields.Categories, row.Categories):
+			row.Errors = append(row.Errors, fmt.Sprintf("categories differ from an earlier row of product %s", p.code))
+		}
+
+		if row.SKU == "" {
+			continue
+		}
+		names := make([]string, len(row.Options))
+		for i, option := range row.Options {
+			names[i] = option.Name
+		}
+		slices.Sort(names)
+		if len(slices.Compact(slices.Clone(names))) != len(names) {
+			row.Errors = append(row.Errors, "an option is set twice")
+			continue
+		}
+		if optionsLine == 0 {
+			optionsLine = row.Line
+			for _, option := range row.Options {
+				p.options = append(p.options, option.Name)
+			}
+			continue
+		}
+		expected := slices.Clone(p.options)
+		slices.Sort(expected)
+		if !slices.Equal(expected, names) {
+			row.Errors = append(row.Errors, fmt.Sprintf("options differ from the variant on line %d", optionsLine))
+		}
+	}
+}
+
+// sameCodes reports whether a and b hold the same codes, in any order.
+func sameCodes(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// validateStored checks the rows against the catalog: new products need a price, categories
+// must exist and SKUs must not belong to a variant of another product.
+func (i *Importer) validateStored(rows []Row, products []*product, skus map[string]int) error {
+	stored := make(map[string]bool)
+	for _, p := range products {
+		if p.fields.Price != nil {
+			continue
+		}
+		_, err := repository.NewProducts(i.db).GetProductByCode(p.code, repository.AllProducts)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		stored[p.code] = err == nil
+	}
+
+	categories := repository.NewCategories(i.db)
+	known := make(map[string]bool)
+	for _, p := range products {
+		for _, code := range append([]string{category(p.fields)}, p.fields.Categories...) {
+			if _, ok := known[code]; ok || code == "" {
+				continue
+			}
+			_, err := categories.GetCategoryByCode(code)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			known[code] = err == nil
+		}
+	}
+
+	owners := make(map[string]string)
+	if len(skus) > 0 {
+		list := make([]string, 0, len(skus))
+		for sku := range skus {
+			list = append(list, sku)
+		}
+		stored, err := repository.NewVariants(i.db).GetVariantsBySKU(list, repository.AllProducts)
+		if err != nil {
+			return err
+		}
+		for _, variant := range stored {
+			owners[variant.SKU] = variant.Product.Code
+		}
+	}
+
+	checkCatalog(rows, products, stored, known, owners)
+	return nil
+}
+
+// checkCatalog records the products without price that are not stored, and the rows that set a
+// category or further category not in known, or a SKU that owners, by SKU, gives to another
+// product.
+func checkCatalog(rows []Row, products []*product, stored, known map[string]bool, owners map[string]string) {
+	for _, p := range products {
+		if p.fields.Price == nil && !stored[p.code] {
+			rows[p.rows[0]].Errors = append(rows[p.rows[0]].Errors, "price is required for a new product")
+		}
+		if code := category(p.fields); code != "" && !known[code] {
+			for _, n := range p.rows {
+				if category(rows[n]) == code {
+					rows[n].Errors = append(rows[n].Errors, fmt.Sprintf("unknown category %s", code))
+				}
+			}
+		}
+		for _, code := range p.fields.Categories {
+			if known[code] {
+				continue
+			}
+			for _, n := range p.rows {
+				if slices.Contains(rows[n].Categories, code) {
+					rows[n].Errors = append(rows[n].Errors, fmt.Sprintf("unknown category %s", code))
+				}
+			}
+		}
+	}
+
+	for n := range rows {
+		row := &rows[n]
+		if owner, ok := owners[row.SKU]; ok && owner != row.Code {
+			row.Errors = append(row.Errors, fmt.Sprintf("sku %s belongs to product %s", row.SKU, owner))
+		}
+	}
+}
+
+// category returns the category code of a row, empty when it sets none.
+func category(row Row) string {
+	if row.Category == nil {
+		return ""
+	}
+	return *row.Category
+}
+
+// upsert creates or updates a product and the variants of its rows, recording their statuses.
+func upsert(tx *gorm.DB, p *product, rows []Row, actor string, report *Report) error {
+	products := repository.NewProducts(tx)
+	stored, err := products.GetProductByCode(p.code, repository.AllProducts)
+	created := errors.Is(err, repository.ErrNotFound)
+	if created {
+		status := models.StatusDraft
+		if p.fields.Status != nil {
+			status = *p.fields.Status
+		}
+		stored, err = &models.Product{Code: p.code, Status: status}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only fields the rows set change; an empty category removes the product from its category
+	if p.fields.Name != nil {
+		stored.Name = *p.fields.Name
+	}
+	if p.fields.Description != nil {
+		stored.Description = *p.fields.Description
+	}
+	if p.fields.Brand != nil {
+		stored.Brand = *p.fields.Brand
+	}
+	if p.fields.Price != nil {
+		stored.Price = *p.fields.Price
+	}
+	if p.fields.Category != nil {
+		stored.Category = nil
+		if *p.fields.Category != "" {
+			stored.Category = &models.Category{Code: *p.fields.Category}
+		}
+	}
+	if len(p.options) > 0 || created {
+		stored.Options = make([]models.ProductOption, len(p.options))
+		for i, name := range p.options {
+			stored.Options[i] = models.ProductOption{Name: name}
+		}
+	}
+
+	if created {
+		err = products.CreateProduct(stored, actor)
+	} else {
+		err = products.UpdateProduct(stored, actor)
+	}
+	if err != nil {
+		return err
+	}
+	if len(p.fields.Categories) > 0 {
+		if err := products.AttachCategories(p.code, p.fields.Categories); err != nil {
+			return err
+		}
+	}
+
+	variants := repository.NewVariants(tx)
+	for _, n := range p.rows {
+		row := rows[n]
+		if row.SKU == "" {
+			report.Rows[n].Status = status(created)
+			continue
+		}
+		variantCreated, err := upsertVariant(variants, p.code, row, actor)
+		if err != nil {
+			return fmt.Errorf("variant %s: %w", row.SKU, err)
+		}
+		report.Rows[n].Status = status(variantCreated)
+	}
+	return nil
+}
+
+// upsertVariant creates or updates the variant of a row and reports whether it was created.
+func upsertVariant(variants *repository.Variants, productCode string, row Row, actor string) (bool, error) {
+	variant, err := variants.GetVariant(productCode, row.SKU)
+	created := errors.Is(err, repository.ErrNotFound)
+	if created {
+		variant, err = &models.Variant{SKU: row.SKU}, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	setVariantFields(variant, row)
+	if created {
+		return true, variants.CreateVariant(productCode, variant, actor)
+	}
+	return false, variants.UpdateVariant(variant, actor)
+}
+
+// setVariantFields copies the variant fields the row sets onto the variant; a variant price,
+// name or options the row leaves out keep their stored value.
+func setVariantFields(variant *models.Variant, row Row) {
+	if row.VariantName != "" {
+		variant.Name = row.VariantName
+	}
+	if row.VariantPrice != nil {
+		variant.Price = decimal.NewNullDecimal(*row.VariantPrice)
+	}
+	if len(row.Options) > 0 {
+		variant.Options = make([]models.VariantOption, len(row.Options))
+		for i, option := range row.Options {
+			variant.Options[i] = models.VariantOption{Name: option.Name, Value: option.Value}
+		}
+	}
+}
+
+func status(created bool) string {
+	if created {
+		return StatusCreated
+	}
+	return StatusUpdated
+}
+
+// skip marks the rows with errors invalid and the others skipped.
+func (r *Report) skip(rows []Row) {
+	for n, row := range rows {
+		r.Rows[n].Status = StatusSkipped
+		if len(row.Errors) > 0 {
+			r.Rows[n].Status = StatusInvalid
+			r.Rows[n].Errors = row.Errors
+		}
+	}
+}
+
+// fail marks the rows of a rolled back batch failed with its error.
+func (r *Report) fail(batch []*product, err error) {
+	for _, product := range batch {
+		for _, n := range product.rows {
+			r.Rows[n].Status = StatusFailed
+			r.Rows[n].Errors = []string{err.Error()}
+		}
+	}
+}
+
+// count tallies the rows by status.
+func (r *Report) count() {
+	for _, row := range r.Rows {
+		switch row.Status {
+		case StatusCreated:
+			r.Created++
+		case StatusUpdated:
+			r.Updated++
+		case StatusInvalid:
+			r.Invalid++
+		case StatusFailed:
+			r.Failed++
+		case StatusSkipped:
+			r.Skipped++
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func text(value string) *string {
+	return &value
+}
+
+func price(value string) *decimal.Decimal {
+	amount := decimal.RequireFromString(value)
+	return &amount
+}
+
+func TestValidateRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []Row
+		errors [][]string
+	}{
+		{
+			name: "accepts a product with variants repeating or leaving out its fields",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Name: text("Shirt"), Price: price("10"), SKU: "SKU001A", Options: []Option{{Name: "size", Value: "M"}}},
+				{Line: 3, Code: "PROD001", Name: text("Shirt"), SKU: "SKU001B", Options: []Option{{Name: "size", Value: "L"}}},
+			},
+			errors: [][]string{nil, nil},
+		},
+		{
+			name: "rejects a sku used twice",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Price: price("10"), SKU: "SKU001A", VariantName: "A"},
+				{Line: 3, Code: "PROD002", Price: price("10"), SKU: "SKU001A", VariantName: "A"},
+			},
+			errors: [][]string{nil, {"sku SKU001A is already used on line 2"}},
+		},
+		{
+			name: "rejects fields that differ from an earlier row",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Name: text("Shirt"), Category: text("SHIRTS"), Price: price("10"), SKU: "SKU001A", VariantName: "A"},
+				{Line: 3, Code: "PROD001", Name: text("Blouse"), Category: text(""), Price: price("10.00"), SKU: "SKU001B", VariantName: "B"},
+				{Line: 4, Code: "PROD001", Price: price("11"), SKU: "SKU001C", VariantName: "C"},
+				{Line: 5, Code: "PROD001", Categories: []string{"SALE"}, SKU: "SKU001D", VariantName: "D"},
+				{Line: 6, Code: "PROD001", Categories: []string{"SALE", "NEW"}, SKU: "SKU001E", VariantName: "E"},
+			},
+			errors: [][]string{
+				nil,
+				{"name differs from an earlier row of product PROD001", "category differs from an earlier row of product PROD001"},
+				{"price differs from an earlier row of product PROD001"},
+				nil,
+				{"categories differ from an earlier row of product PROD001"},
+			},
+		},
+		{
+			name: "rejects variants whose options differ from the first variant",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Price: price("10"), SKU: "SKU001A", Options: []Option{{Name: "size", Value: "M"}, {Name: "color", Value: "Red"}}},
+				{Line: 3, Code: "PROD001", SKU: "SKU001B", Options: []Option{{Name: "color", Value: "Blue"}, {Name: "size", Value: "L"}}},
+				{Line: 4, Code: "PROD001", SKU: "SKU001C", Options: []Option{{Name: "size", Value: "S"}}},
+				{Line: 5, Code: "PROD001", SKU: "SKU001D", Options: []Option{{Name: "size", Value: "S"}, {Name: "size", Value: "XS"}}},
+			},
+			errors: [][]string{nil, nil, {"options differ from the variant on line 2"}, {"an option is set twice"}},
+		},
+		{
+			name: "rejects negative prices",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Price: price("-1"), SKU: "SKU001A", VariantName: "A", VariantPrice: price("-2")},
+			},
+			errors: [][]string{{"price must not be negative", "variantPrice must not be negative"}},
+		},
+		{
+			name: "rejects variant fields without sku and variants without name or options",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Price: price("10"), VariantPrice: price("12")},
+				{Line: 3, Code: "PROD002", Price: price("10"), SKU: "SKU002A"},
+			},
+			errors: [][]string{{"variant fields need a sku"}, {"variantName is required for a variant without options"}},
+		},
+		{
+			name: "keeps the errors of parsing",
+			rows: []Row{
+				{Line: 2, Code: "PROD001", Price: price("10"), Errors: []string{`variantPrice: "abc" is not a decimal`}},
+			},
+			errors: [][]string{{`variantPrice: "abc" is not a decimal`}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validateRows(test.rows, group(test.rows))
+
+			for n, row := range test.rows {
+				assert.Equal(t, test.errors[n], row.Errors, "line %d", row.Line)
+			}
+		})
+	}
+
+	t.Run("rejects rows breaking the field rules", func(t *testing.T) {
+		rows := []Row{
+			{Line: 2, Price: price("10")},
+			{Line: 3, Code: "PROD002", Price: price("10"), Status: text("deleted")},
+		}
+
+		validateRows(rows, group(rows))
+
+		assert.Len(t, rows[0].Errors, 1)
+		assert.Contains(t, rows[0].Errors[0], "'Code' failed on the 'required' tag")
+		assert.Len(t, rows[1].Errors, 1)
+		assert.Contains(t, rows[1].Errors[0], "'Status' failed on the 'oneof' tag")
+	})
+
+	t.Run("returns the line of every sku", func(t *testing.T) {
+		rows := []Row{
+			{Line: 2, Code: "PROD001", Price: price("10")},
+			{Line: 3, Code: "PROD001", SKU: "SKU001A", VariantName: "A"},
+		}
+
+		assert.Equal(t, map[string]int{"SKU001A": 3}, validateRows(rows, group(rows)))
+	})
+}
+
+func TestMerge(t *testing.T) {
+	rows := []Row{
+		{Line: 2, Code: "PROD001", Brand: text("Acme"), Price: price("10"), SKU: "SKU001A", Options: []Option{{Name: "size", Value: "M"}, {Name: "color", Value: "Red"}}},
+		{Line: 3, Code: "PROD001", Name: text("Shirt"), Description: text(""), SKU: "SKU001B", Options: []Option{{Name: "color", Value: "Red"}, {Name: "size", Value: "L"}}},
+	}
+	p := group(rows)[0]
+
+	p.merge(rows)
+
+	assert.Equal(t, "Shirt", *p.fields.Name)
+	assert.Equal(t, "", *p.fields.Description)
+	assert.Equal(t, "Acme", *p.fields.Brand)
+	assert.Nil(t, p.fields.Category, "a field no row sets stays unset")
+	assert.Nil(t, p.fields.Status)
+	assert.True(t, p.fields.Price.Equal(decimal.RequireFromString("10")))
+	assert.Equal(t, []string{"size", "color"}, p.options)
+}
+
+func TestSetVariantFields(t *testing.T) {
+	stored := func() *models.Variant {
+		return &models.Variant{
+			SKU:     "SKU001A",
+			Name:    "Small",
+			Price:   decimal.NewNullDecimal(decimal.RequireFromString("12.50")),
+			Options: []models.VariantOption{{Name: "size", Value: "S"}},
+		}
+	}
+
+	t.Run("re-importing a variant without price keeps its stored price", func(t *testing.T) {
+		variant := stored()
+
+		setVariantFields(variant, Row{Code: "PROD001", SKU: "SKU001A", VariantName: "Small"})
+
+		assert.True(t, variant.Price.Valid)
+		assert.True(t, variant.Price.Decimal.Equal(decimal.RequireFromString("12.50")))
+		assert.Equal(t, "Small", variant.Name)
+		assert.Equal(t, []models.VariantOption{{Name: "size", Value: "S"}}, variant.Options)
+	})
+
+	t.Run("fields the row sets replace the stored ones", func(t *testing.T) {
+		variant := stored()
+
+		setVariantFields(variant, Row{
+			Code: "PROD001", SKU: "SKU001A", VariantPrice: price("9.99"),
+			Options: []Option{{Name: "size", Value: "M"}},
+		})
+
+		assert.True(t, variant.Price.Decimal.Equal(decimal.RequireFromString("9.99")))
+		assert.Equal(t, "Small", variant.Name, "a row without variantName keeps the name")
+		assert.Equal(t, []models.VariantOption{{Name: "size", Value: "M"}}, variant.Options)
+	})
+}
+
+func TestCheckCatalog(t *testing.T) {
+	rows := []Row{
+		{Line: 2, Code: "PROD001", Category: text("SHIRTS"), SKU: "SKU001A"},
+		{Line: 3, Code: "PROD001", Categories: []string{"SHIRTS", "GONE"}, SKU: "SKU001B"},
+		{Line: 4, Code: "PROD002", Category: text("UNKNOWN"), SKU: "SKU002A"},
+		{Line: 5, Code: "PROD002", Category: text("UNKNOWN"), SKU: "SKU009A"},
+		{Line: 6, Code: "PROD003", Category: text(""), Price: price("10")},
+		{Line: 7, Code: "PROD004", SKU: "SKU004A"},
+		{Line: 8, Code: "PROD004", SKU: "SKU004B"},
+	}
+	products := group(rows)
+	validateRows(rows, products)
+	for n := range rows {
+		rows[n].Errors = nil
+	}
+
+	checkCatalog(rows, products,
+		map[string]bool{"PROD001": true, "PROD002": true},
+		map[string]bool{"SHIRTS": true, "UNKNOWN": false},
+		map[string]string{"SKU001A": "PROD001", "SKU009A": "PROD009"})
+
+	assert.Nil(t, rows[0].Errors, "a sku of the same product is updated, keeping the stored price")
+	assert.Equal(t, []string{"unknown category GONE"}, rows[1].Errors)
+	assert.Equal(t, []string{"unknown category UNKNOWN"}, rows[2].Errors)
+	assert.Equal(t, []string{"unknown category UNKNOWN", "sku SKU009A belongs to product PROD009"}, rows[3].Errors)
+	assert.Nil(t, rows[4].Errors, "an empty category removes the category")
+	assert.Equal(t, []string{"price is required for a new product"}, rows[5].Errors)
+	assert.Nil(t, rows[6].Errors)
+}
+
+func TestBatches(t *testing.T) {
+	products := []*product{{code: "PROD001"}, {code: "PROD002"}, {code: "PROD003"}}
+
+	assert.Equal(t, [][]*product{products}, batches(products, 0))
+	assert.Equal(t, [][]*product{products[:2], products[2:]}, batches(products, 2))
+	assert.Equal(t, [][]*product{products[:1], products[1:2], products[2:]}, batches(products, 1))
+	assert.Empty(t, batches(nil, 0))
+}
+
+func TestReportStatuses(t *testing.T) {
+	newReport := func(rows []Row) *Report {
+		report := &Report{Rows: make([]RowReport, len(rows))}
+		for n, row := range rows {
+			report.Rows[n] = RowReport{Line: row.Line, Code: row.Code, SKU: row.SKU}
+		}
+		return report
+	}
+
+	t.Run("skips valid rows when some are invalid", func(t *testing.T) {
+		rows := []Row{
+			{Line: 2, Code: "PROD001"},
+			{Line: 3, Code: "PROD002", Errors: []string{"price is required for a new product"}},
+		}
+		assert.False(t, valid(rows))
+		report := newReport(rows)
+
+		report.skip(rows)
+		report.count()
+
+		assert.Equal(t, StatusSkipped, report.Rows[0].Status)
+		assert.Empty(t, report.Rows[0].Errors)
+		assert.Equal(t, StatusInvalid, report.Rows[1].Status)
+		assert.Equal(t, []string{"price is required for a new product"}, report.Rows[1].Errors)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, 1, report.Invalid)
+		assert.False(t, report.Succeeded())
+	})
+
+	t.Run("fails the rows of a rolled back batch only", func(t *testing.T) {
+		rows := []Row{
+			{Line: 2, Code: "PROD001", SKU: "SKU001A"},
+			{Line: 3, Code: "PROD001", SKU: "SKU001B"},
+			{Line: 4, Code: "PROD002"},
+		}
+		assert.True(t, valid(rows))
+		products := group(rows)
+		report := newReport(rows)
+		report.Rows[0].Status = StatusCreated
+		report.Rows[1].Status = StatusUpdated
+		report.Rows[2].Status = StatusCreated
+
+		report.fail(batches(products, 1)[0], errors.New("product PROD001: conflict"))
+		report.count()
+
+		assert.Equal(t, StatusFailed, report.Rows[0].Status)
+		assert.Equal(t, []string{"product PROD001: conflict"}, report.Rows[0].Errors)
+		assert.Equal(t, StatusFailed, report.Rows[1].Status)
+		assert.Equal(t, StatusCreated, report.Rows[2].Status)
+		assert.Equal(t, 2, report.Failed)
+		assert.Equal(t, 1, report.Created)
+		assert.False(t, report.Succeeded())
+	})
+
+	t.Run("succeeds when every row is created or updated", func(t *testing.T) {
+		report := &Report{Rows: []RowReport{{Status: StatusCreated}, {Status: StatusUpdated}, {Status: StatusUpdated}}}
+
+		report.count()
+
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 2, report.Updated)
+		assert.True(t, report.Succeeded())
+	})
+}
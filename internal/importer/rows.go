@@ -0,0 +1,263 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Formats of import files.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Formats lists the supported import file formats.
+var Formats = []string{FormatCSV, FormatNDJSON}
+
+// optionColumnPrefix marks CSV columns holding a variant option, e.g. option:size.
+const optionColumnPrefix = "option:"
+
+// csvColumns are the CSV columns besides the option columns; code is required.
+var csvColumns = []string{"code", "name", "description", "brand", "price", "category", "categories", "status", "sku", "variantName", "variantPrice"}
+
+// categorySeparator separates the category codes of the CSV categories column.
+const categorySeparator = "|"
+
+// maxLineSize is the longest NDJSON line that is read.
+const maxLineSize = 1 << 20
+
+// Row is a line of an import file: a product and, when SKU is set, one of its variants.
+// Rows of the same product repeat its fields or leave them out.
+type Row struct {
+	// Line is the line of the row in the file, counting from 1.
+	Line int
+	Code string `validate:"required,max=32"`
+	// Name, Description, Brand, Category, Categories and Status are nil when the row leaves them
+	// out: a missing CSV column or empty cell, or a missing or null NDJSON field. Categories are
+	// further categories to assign the product to.
+	Name         *string `validate:"omitnil,max=256"`
+	Description  *string
+	Brand        *string `validate:"omitnil,max=128"`
+	Price        *decimal.Decimal
+	Category     *string
+	Categories   []string `validate:"dive,required"`
+	Status       *string  `validate:"omitnil,oneof=draft published archived"`
+	SKU          string   `validate:"max=32"`
+	VariantName  string   `validate:"max=256"`
+	VariantPrice *decimal.Decimal
+	Options      []Option `validate:"dive"`
+
+	// Errors holds what is wrong with the row; rows with errors are not imported.
+	Errors []string
+}
+
+// Option is the value a variant row takes for an option of its product.
+type Option struct {
+	Name  string `json:"name" validate:"required,max=64"`
+	Value string `json:"value" validate:"required,max=64"`
+}
+
+// record is a row as read from a file, before its prices are parsed.
+type record struct {
+	Code         string          `json:"code"`
+	Name         *string         `json:"name"`
+	Description  *string         `json:"description"`
+	Brand        *string         `json:"brand"`
+	Price        json.RawMessage `json:"price"`
+	Category     *string         `json:"category"`
+	Categories   []string        `json:"categories"`
+	Status       *string         `json:"status"`
+	SKU          string          `json:"sku"`
+	VariantName  string          `json:"variantName"`
+	VariantPrice json.RawMessage `json:"variantPrice"`
+	Options      []Option        `json:"options"`
+}
+
+// Parse reads the rows of an import file in the given format.
+func Parse(r io.Reader, format string) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatNDJSON:
+		return ParseNDJSON(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// ParseCSV reads rows from CSV with a header line naming the columns. Besides the fixed columns,
+// option:<name> columns hold variant options. A malformed file is an error; a row with a bad
+// price is returned with the error recorded.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		if !slices.Contains(csvColumns, header[i]) && !strings.HasPrefix(header[i], optionColumnPrefix) {
+			return nil, fmt.Errorf("unknown column %q", header[i])
+		}
+	}
+	if !slices.Contains(header, "code") {
+		return nil, fmt.Errorf("column %q is required", "code")
+	}
+
+	var rows []Row
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		var rec record
+		for i, value := range fields {
+			value = strings.TrimSpace(value)
+			switch column := header[i]; column {
+			case "code":
+				rec.Code = value
+			case "name":
+				rec.Name = present(value)
+			case "description":
+				rec.Description = present(value)
+			case "brand":
+				rec.Brand = present(value)
+			case "price":
+				rec.Price = quoted(value)
+			case "category":
+				rec.Category = present(value)
+			case "categories":
+				rec.Categories = splitCodes(value)
+			case "status":
+				rec.Status = present(value)
+			case "sku":
+				rec.SKU = value
+			case "variantName":
+				rec.VariantName = value
+			case "variantPrice":
+				rec.VariantPrice = quoted(value)
+			default:
+				if value != "" {
+					rec.Options = append(rec.Options, Option{Name: strings.TrimPrefix(column, optionColumnPrefix), Value: value})
+				}
+			}
+		}
+		rows = append(rows, newRow(line, rec))
+	}
+}
+
+// ParseNDJSON reads rows from newline delimited JSON, one object per line; options are a list
+// of name and value objects. Blank lines are skipped. A line that is not a valid row is
+// returned with the error recorded.
+func ParseNDJSON(r io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var rows []Row
+	for line := 1; scanner.Scan(); line++ {
+		content := bytes.TrimSpace(scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		var rec record
+		if err := decoder.Decode(&rec); err != nil {
+			rows = append(rows, Row{Line: line, Code: rec.Code, SKU: rec.SKU, Errors: []string{"invalid JSON: " + err.Error()}})
+			continue
+		}
+		rows = append(rows, newRow(line, rec))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	return rows, nil
+}
+
+// newRow parses the prices of a record, recording the ones that are not decimals.
+func newRow(line int, rec record) Row {
+	row := Row{
+		Line:        line,
+		Code:        rec.Code,
+		Name:        rec.Name,
+		Description: rec.Description,
+		Brand:       rec.Brand,
+		Category:    rec.Category,
+		Categories:  rec.Categories,
+		Status:      rec.Status,
+		SKU:         rec.SKU,
+		VariantName: rec.VariantName,
+		Options:     rec.Options,
+	}
+
+	var err error
+	if row.Price, err = parseDecimal(rec.Price); err != nil {
+		row.Errors = append(row.Errors, "price: "+err.Error())
+	}
+	if row.VariantPrice, err = parseDecimal(rec.VariantPrice); err != nil {
+		row.Errors = append(row.Errors, "variantPrice: "+err.Error())
+	}
+	return row
+}
+
+// parseDecimal reads a JSON number or string as a decimal; empty values and null are nil.
+func parseDecimal(raw json.RawMessage) (*decimal.Decimal, error) {
+	value := strings.TrimSpace(string(raw))
+	if value == "" || value == "null" || value == `""` {
+		return nil, nil
+	}
+
+	var amount decimal.Decimal
+	if err := amount.UnmarshalJSON([]byte(value)); err != nil {
+		return nil, fmt.Errorf("%s is not a decimal", value)
+	}
+	return &amount, nil
+}
+
+// present returns a CSV value, or nil for an empty cell.
+func present(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// splitCodes returns the codes of a CSV cell separated by categorySeparator, or nil for an
+// empty cell.
+func splitCodes(value string) []string {
+	var codes []string
+	for _, code := range strings.Split(value, categorySeparator) {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// quoted wraps a CSV value as a JSON string so it is parsed like an NDJSON price.
+func quoted(value string) json.RawMessage {
+	encoded, _ := json.Marshal(value)
+	return encoded
+}
@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("reads rows with options and their lines", func(t *testing.T) {
+		file := "code, name ,price,category,sku,variantPrice,option:size,option:color\n" +
+			"PROD001,Shirt,10.99,CLOTHING,SKU001A,,M,Red\n" +
+			"\n" +
+			"PROD001,,,,SKU001B, 12.50 ,L,\n"
+
+		rows, err := ParseCSV(strings.NewReader(file))
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, "PROD001", rows[0].Code)
+		assert.Equal(t, "Shirt", *rows[0].Name)
+		assert.True(t, rows[0].Price.Equal(decimal.RequireFromString("10.99")))
+		assert.Equal(t, "CLOTHING", *rows[0].Category)
+		assert.Nil(t, rows[0].VariantPrice)
+		assert.Equal(t, []Option{{Name: "size", Value: "M"}, {Name: "color", Value: "Red"}}, rows[0].Options)
+
+		assert.Equal(t, 4, rows[1].Line)
+		assert.Nil(t, rows[1].Name, "an empty cell leaves the field out")
+		assert.Nil(t, rows[1].Price)
+		assert.Nil(t, rows[1].Category)
+		assert.Nil(t, rows[1].Description, "a missing column leaves the field out")
+		assert.True(t, rows[1].VariantPrice.Equal(decimal.RequireFromString("12.50")))
+		assert.Equal(t, []Option{{Name: "size", Value: "L"}}, rows[1].Options)
+		assert.Empty(t, rows[1].Errors)
+	})
+
+	t.Run("records a bad decimal on its row", func(t *testing.T) {
+		rows, err := ParseCSV(strings.NewReader("code,price,sku,variantPrice\nPROD001,abc,SKU001A,1.2.3\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"price: \"abc\" is not a decimal", "variantPrice: \"1.2.3\" is not a decimal"}, rows[0].Errors)
+	})
+
+	t.Run("reads further categories separated by a bar", func(t *testing.T) {
+		rows, err := ParseCSV(strings.NewReader("code,categories\nPROD001,SALE | SHIRTS\nPROD002,\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"SALE", "SHIRTS"}, rows[0].Categories)
+		assert.Nil(t, rows[1].Categories, "an empty cell leaves the field out")
+	})
+
+	t.Run("reads a file without price column", func(t *testing.T) {
+		rows, err := ParseCSV(strings.NewReader("code,sku,variantName\nPROD001,SKU001A,Small\n"))
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 1)
+		assert.Nil(t, rows[0].Price)
+	})
+
+	t.Run("reads a header without rows", func(t *testing.T) {
+		rows, err := ParseCSV(strings.NewReader("code,price\n"))
+
+		assert.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+
+	tests := []struct {
+		name string
+		file string
+		err  string
+	}{
+		{"an empty file", "", "file is empty"},
+		{"an unknown column", "code,price,colour\n", `unknown column "colour"`},
+		{"a missing code column", "name,price\n", `column "code" is required`},
+	}
+	for _, test := range tests {
+		t.Run("rejects "+test.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(test.file))
+
+			assert.EqualError(t, err, test.err)
+		})
+	}
+
+	t.Run("rejects a row with too many fields", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("code,price\nPROD001,10,extra\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParseNDJSON(t *testing.T) {
+	t.Run("reads rows and skips blank lines", func(t *testing.T) {
+		file := `{"code": "PROD001", "name": "Shirt", "price": 10.99, "category": "", "categories": ["SALE"], "options": [{"name": "size", "value": "M"}], "sku": "SKU001A"}` + "\n" +
+			"\n" +
+			`{"code": "PROD001", "name": null, "price": "11", "variantPrice": "12.50", "sku": "SKU001B", "variantName": "Large"}` + "\n"
+
+		rows, err := ParseNDJSON(strings.NewReader(file))
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, 1, rows[0].Line)
+		assert.Equal(t, "Shirt", *rows[0].Name)
+		assert.True(t, rows[0].Price.Equal(decimal.RequireFromString("10.99")))
+		assert.Equal(t, "", *rows[0].Category, "an empty string sets the field")
+		assert.Equal(t, []string{"SALE"}, rows[0].Categories)
+		assert.Nil(t, rows[1].Categories)
+		assert.Nil(t, rows[0].Brand, "a missing field leaves it out")
+		assert.Equal(t, []Option{{Name: "size", Value: "M"}}, rows[0].Options)
+
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Nil(t, rows[1].Name, "null leaves the field out")
+		assert.True(t, rows[1].Price.Equal(decimal.RequireFromString("11")))
+		assert.True(t, rows[1].VariantPrice.Equal(decimal.RequireFromString("12.50")))
+		assert.Equal(t, "Large", rows[1].VariantName)
+	})
+
+	t.Run("records invalid lines on their row", func(t *testing.T) {
+		file := `{"code": "PROD001", "price": "abc"}` + "\n" +
+			`{"code": "PROD002", "colour": "red"}` + "\n" +
+			`{"code": "PROD003",` + "\n"
+
+		rows, err := ParseNDJSON(strings.NewReader(file))
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, []string{`price: "abc" is not a decimal`}, rows[0].Errors)
+		assert.Equal(t, "PROD002", rows[1].Code)
+		assert.Len(t, rows[1].Errors, 1)
+		assert.Contains(t, rows[1].Errors[0], "invalid JSON: ")
+		assert.Contains(t, rows[1].Errors[0], "colour")
+		assert.Equal(t, 3, rows[2].Line)
+		assert.Len(t, rows[2].Errors, 1)
+		assert.Contains(t, rows[2].Errors[0], "invalid JSON: ")
+	})
+
+	t.Run("rejects a file without rows", func(t *testing.T) {
+		_, err := ParseNDJSON(strings.NewReader("\n  \n"))
+
+		assert.EqualError(t, err, "file is empty")
+	})
+}
+
+func TestParse(t *testing.T) {
+	rows, err := Parse(strings.NewReader("code,price\nPROD001,10\n"), FormatCSV)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	_, err = Parse(strings.NewReader(""), "xml")
+	assert.EqualError(t, err, `unknown format "xml"`)
+}
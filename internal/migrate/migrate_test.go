@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		expected []Migration
+		err      string
+	}{
+		{
+			name: "orders migrations by version and pairs down files",
+			files: fstest.MapFS{
+				"010-product-search.sql":      {Data: []byte("CREATE INDEX search;")},
+				"002-categories.sql":          {Data: []byte("CREATE TABLE categories;")},
+				"002-categories.down.sql":     {Data: []byte("DROP TABLE categories;")},
+				"001-products.sql":            {Data: []byte("CREATE TABLE products;")},
+				"README.md":                   {Data: []byte("not a migration")},
+				"notes.sql":                   {Data: []byte("not versioned")},
+				"003-nested/ignored.sql":      {Data: []byte("in a directory")},
+				"010-product-search.down.sql": {Data: []byte("DROP INDEX search;")},
+			},
+			expected: []Migration{
+				{Version: 1, Name: "products", Up: "CREATE TABLE products;", Checksum: checksum("CREATE TABLE products;")},
+				{Version: 2, Name: "categories", Up: "CREATE TABLE categories;", Down: "DROP TABLE categories;", Checksum: checksum("CREATE TABLE categories;")},
+				{Version: 10, Name: "product-search", Up: "CREATE INDEX search;", Down: "DROP INDEX search;", Checksum: checksum("CREATE INDEX search;")},
+			},
+		},
+		{
+			name:     "loads an empty directory",
+			files:    fstest.MapFS{},
+			expected: []Migration{},
+		},
+		{
+			name: "rejects two migrations with the same version",
+			files: fstest.MapFS{
+				"001-products.sql": {Data: []byte("CREATE TABLE products;")},
+				"001-variants.sql": {Data: []byte("CREATE TABLE variants;")},
+			},
+			err: "migrations products and variants share version 1",
+		},
+		{
+			name: "rejects a down file without up file",
+			files: fstest.MapFS{
+				"001-products.sql":        {Data: []byte("CREATE TABLE products;")},
+				"002-categories.down.sql": {Data: []byte("DROP TABLE categories;")},
+			},
+			err: "down migration 2 has no up migration",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			migrations, err := Load(test.files)
+
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, migrations)
+		})
+	}
+}
+
+func TestLoadRepositoryMigrations(t *testing.T) {
+	migrations, err := Load(os.DirFS("../sql"))
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "versions are consecutive")
+	}
+}
+
+func TestMigrationString(t *testing.T) {
+	assert.Equal(t, "012-product-search", Migration{Version: 12, Name: "product-search"}.String())
+}
+
+func TestVerify(t *testing.T) {
+	migrator := New(nil, []Migration{
+		{Version: 1, Name: "products", Checksum: checksum("CREATE TABLE products;")},
+		{Version: 2, Name: "categories", Checksum: checksum("CREATE TABLE categories;")},
+	}, nil)
+
+	tests := []struct {
+		name    string
+		applied []appliedMigration
+		err     error
+	}{
+		{
+			name: "accepts nothing applied",
+		},
+		{
+			name: "accepts unchanged applied migrations",
+			applied: []appliedMigration{
+				{Version: 1, Name: "products", Checksum: checksum("CREATE TABLE products;")},
+				{Version: 2, Name: "categories", Checksum: checksum("CREATE TABLE categories;")},
+			},
+		},
+		{
+			name: "rejects an applied migration whose file changed",
+			applied: []appliedMigration{
+				{Version: 1, Name: "products", Checksum: checksum("CREATE TABLE products (id INT);")},
+			},
+			err: ErrChecksumMismatch,
+		},
+		{
+			name: "rejects an applied migration without file",
+			applied: []appliedMigration{
+				{Version: 1, Name: "products", Checksum: checksum("CREATE TABLE products;")},
+				{Version: 3, Name: "variants", Checksum: checksum("CREATE TABLE variants;")},
+			},
+			err: ErrMissingMigration,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			applied := make(map[int]appliedMigration, len(test.applied))
+			for _, row := range test.applied {
+				applied[row.Version] = row
+			}
+
+			err := migrator.verify(applied)
+
+			if test.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration is a versioned schema change. Up is read from NNN-name.sql and Down, which reverts
+// it, from NNN-name.down.sql next to it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of Up; applied migrations must not change afterwards.
+	Checksum string
+}
+
+// migrationFile matches NNN-name.sql and NNN-name.down.sql.
+var migrationFile = regexp.MustCompile(`^(\d+)-(.+?)(\.down)?\.sql$`)
+
+// Load reads the migrations in a directory, ordered by version. Every version needs an up file
+// and no two migrations may share a version; the down file is optional.
+func Load(dir fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	downs := make(map[int]string)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] != "" {
+			downs[version] = string(content)
+			continue
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", existing.Name, match[2], version)
+		}
+		sum := sha256.Sum256(content)
+		byVersion[version] = &Migration{
+			Version:  version,
+			Name:     match[2],
+			Up:       string(content),
+			Checksum: hex.EncodeToString(sum[:]),
+		}
+	}
+
+	for version, down := range downs {
+		if _, ok := byVersion[version]; !ok {
+			return nil, fmt.Errorf("down migration %d has no up migration", version)
+		}
+		byVersion[version].Down = down
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// String names the migration like its file, e.g. 012-product-search.
+func (m Migration) String() string {
+	return fmt.Sprintf("%03d-%s", m.Version, m.Name)
+}
@@ -0,0 +1,309 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrChecksumMismatch is returned when an applied migration file has changed since it was applied.
+	ErrChecksumMismatch = errors.New("applied migration has changed")
+	// ErrMissingMigration is returned when an applied migration has no file anymore.
+	ErrMissingMigration = errors.New("applied migration is missing")
+	// ErrIrreversible is returned when a migration to revert has no down file.
+	ErrIrreversible = errors.New("migration has no down migration")
+)
+
+// lockID identifies the advisory lock held while migrating; any constant shared by all instances works.
+const lockID = 7215340022
+
+// createTableSQL records which migrations are applied, with the checksum of what was run.
+const createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name VARCHAR(256) NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string
+	AppliedAt time.Time `gorm:"default:now()"`
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status is the state of a migration known from its file, the database or both.
+type Status struct {
+	Version int
+	Name    string
+	// AppliedAt is nil while the migration is pending.
+	AppliedAt *time.Time
+	// Modified is set when the file changed after the migration was applied.
+	Modified bool
+	// Missing is set when the migration is applied but has no file.
+	Missing bool
+}
+
+// Migrator applies and reverts migrations. Each migration runs in its own transaction together
+// with its schema_migrations row, and an advisory lock keeps concurrent runs apart.
+type Migrator struct {
+	db         database.Database
+	migrations []Migration
+	logger     *log.Logger
+}
+
+func New(db database.Database, migrations []Migration, logger *log.Logger) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// Status lists every migration in version order, applied or not.
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.locked(func(conn *gorm.DB, applied map[int]appliedMigration) error {
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if row, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &row.AppliedAt
+				status.Modified = row.Checksum != migration.Checksum
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, row := range applied {
+			statuses = append(statuses, Status{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt, Missing: true})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Up applies every pending migration in version order and returns them.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.Goto(m.latest())
+}
+
+// Down reverts the given number of most recently applied migrations and returns them.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int]appliedMigration) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Goto applies the pending migrations up to and including version and reverts the applied ones
+// after it, so exactly the migrations up to version are applied. Version 0 reverts everything.
+func (m *Migrator) Goto(version int) ([]Migration, error) {
+	if version != 0 && !m.known(version) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int]appliedMigration) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := m.revert(conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := m.apply(conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Baseline records the pending migrations up to and including version as applied without
+// running them, and returns them. It adopts a database whose schema was created before
+// migrations were tracked; later migrations are then applied by Up as usual.
+func (m *Migrator) Baseline(version int) ([]Migration, error) {
+	if !m.known(version) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int]appliedMigration) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			for _, migration := range m.migrations {
+				if _, ok := applied[migration.Version]; ok || migration.Version > version {
+					continue
+				}
+				if err := tx.Create(&appliedMigration{
+					Version:  migration.Version,
+					Name:     migration.Name,
+					Checksum: migration.Checksum,
+				}).Error; err != nil {
+					return fmt.Errorf("recording %s: %w", migration, err)
+				}
+				done = append(done, migration)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range done {
+		m.logger.Printf("Recorded %s as applied", migration)
+	}
+	return done, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, with the applied migrations
+// read after the lock was taken. Other instances wait for the lock.
+func (m *Migrator) locked(fn func(conn *gorm.DB, applied map[int]appliedMigration) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockID).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", lockID)
+
+		if err := conn.Exec(createTableSQL).Error; err != nil {
+			return err
+		}
+
+		var rows []appliedMigration
+		if err := conn.Order("version").Find(&rows).Error; err != nil {
+			return err
+		}
+		applied := make(map[int]appliedMigration, len(rows))
+		for _, row := range rows {
+			applied[row.Version] = row
+		}
+		return fn(conn, applied)
+	})
+}
+
+// verify checks that every applied migration still has its file, unchanged.
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for _, row := range applied {
+		migration, ok := known[row.Version]
+		if !ok {
+			return fmt.Errorf("%w: %03d-%s", ErrMissingMigration, row.Version, row.Name)
+		}
+		if migration.Checksum != row.Checksum {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, migration)
+		}
+	}
+	return nil
+}
+
+// apply runs a migration and records it in one transaction.
+func (m *Migrator) apply(conn *gorm.DB, migration Migration) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&appliedMigration{
+			Version:  migration.Version,
+			Name:     migration.Name,
+			Checksum: migration.Checksum,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("applying %s: %w", migration, err)
+	}
+
+	m.logger.Printf("Applied %s", migration)
+	return nil
+}
+
+// revert runs the down migration and removes its record in one transaction.
+func (m *Migrator) revert(conn *gorm.DB, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %s", ErrIrreversible, migration)
+	}
+
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&appliedMigration{Version: migration.Version}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("reverting %s: %w", migration, err)
+	}
+
+	m.logger.Printf("Reverted %s", migration)
+	return nil
+}
+
+// known reports whether a migration has the version.
+func (m *Migrator) known(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// latest is the highest known version, or 0 without migrations.
+func (m *Migrator) latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
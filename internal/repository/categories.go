@@ -1,20 +1,44 @@
 package repository
 
 import (
+	"errors"
+	"slices"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/database"
 	"github.com/mytheresa/go-hiring-challenge/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoriesInterface interface {
 	GetAllCategories(filter CategoriesFilter) ([]models.Category, int64, error)
+	GetCategoryByCode(code string) (*models.Category, error)
+	GetCategoryTree() ([]models.Category, error)
 	CreateCategory(category *models.Category) error
+	UpdateCategory(category *models.Category) error
+	DeleteCategory(code string, reassignTo *string) error
+	RestoreCategory(code string) (*models.Category, error)
 }
 
 type Categories struct {
 	db database.Database
 }
 
+// CategorySortFields lists the fields categories can be sorted by.
+var CategorySortFields = []string{"code", "name", "createdAt"}
+
+var categorySortColumns = map[string]string{
+	"code":      "categories.code",
+	"name":      "categories.name",
+	"createdAt": "categories.created_at",
+}
+
 type CategoriesFilter struct {
+	// IncludeDeleted also lists deleted categories.
+	IncludeDeleted bool
+	// Sort orders the categories; the id is always the last key.
+	Sort   []common.SortField
 	Offset int
 	Limit  int
 }
@@ -30,14 +54,17 @@ func (r *Categories) GetAllCategories(filter CategoriesFilter) ([]models.Categor
 	var total int64
 
 	query := r.db.Model(&models.Category{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
 
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination
-	if err := query.
+	// Apply sort order and pagination
+	if err := orderBy(query, filter.Sort, categorySortColumns, "categories.id").
 		Offset(filter.Offset).
 		Limit(filter.Limit).
 		Find(&categories).Error; err != nil {
@@ -47,6 +74,209 @@ func (r *Categories) GetAllCategories(filter CategoriesFilter) ([]models.Categor
 	return categories, total, nil
 }
 
+func (r *Categories) GetCategoryByCode(code string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.Where("code = ?", code).
+		Preload("Parent").
+		First(&category).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &category, nil
+}
+
+// GetCategoryTree returns the root categories with their descendants nested in Children.
+func (r *Categories) GetCategoryTree() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Model(&models.Category{}).
+		Order("name").
+		Order("id").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return models.BuildCategoryTree(categories), nil
+}
+
+// CreateCategory inserts a new category. The parent, if any, is looked up by its code.
 func (r *Categories) CreateCategory(category *models.Category) error {
-	return r.db.Create(category).Error
+	if err := r.resolveParent(category); err != nil {
+		return err
+	}
+
+	if err := r.db.Model(category).Omit(clause.Associations).Create(category).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// UpdateCategory persists an existing category. The parent, if any, is looked up by its code
+// and must not be the category itself or one of its descendants.
+func (r *Categories) UpdateCategory(category *models.Category) error {
+	if err := r.resolveParent(category); err != nil {
+		return err
+	}
+
+	if category.ParentID != nil {
+		var ancestorIDs []uint
+		if err := r.db.Raw(ancestorIDsSQL, *category.ParentID).Scan(&ancestorIDs).Error; err != nil {
+			return err
+		}
+		if slices.Contains(ancestorIDs, category.ID) {
+			return ErrCategoryCycle
+		}
+	}
+
+	if err := r.db.Model(category).Omit(clause.Associations).Save(category).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// DeleteCategory soft deletes a category. While products or subcategories still reference it the
+// delete is refused with ErrInUse, unless reassignTo names the category they are moved to first.
+// Moving and deleting happen in a single transaction.
+func (r *Categories) DeleteCategory(code string, reassignTo *string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).
+			First(&category).Error; err != nil {
+			return translateError(err)
+		}
+
+		if reassignTo != nil {
+			var target models.Category
+			if err := tx.Where("code = ?", *reassignTo).First(&target).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrUnknownCategory
+				}
+				return err
+			}
+
+			// Subcategories move along, so the target must not be one of them
+			var ancestorIDs []uint
+			if err := tx.Raw(ancestorIDsSQL, target.ID).Scan(&ancestorIDs).Error; err != nil {
+				return err
+			}
+			if slices.Contains(ancestorIDs, category.ID) {
+				return ErrCategoryCycle
+			}
+
+			// Deleted products and subcategories move too, so restoring them finds a live category
+			if err := tx.Unscoped().Model(&models.Product{}).
+				Where("category_id = ?", category.ID).
+				Update("category_id", target.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(
+				`INSERT INTO product_categories (product_id, category_id)
+				SELECT product_id, ? FROM product_categories WHERE category_id = ?
+				ON CONFLICT DO NOTHING`,
+				target.ID, category.ID,
+			).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM product_categories WHERE category_id = ?", category.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&models.Category{}).
+				Where("parent_id = ?", category.ID).
+				Update("parent_id", target.ID).Error; err != nil {
+				return err
+			}
+		} else {
+			var count int64
+			// Deleted products keep their assignments for when they are restored
+			if err := tx.Table("product_categories").
+				Joins("JOIN products ON products.id = product_categories.product_id AND products.deleted_at IS NULL").
+				Where("product_categories.category_id = ?", category.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrInUse
+			}
+
+			if err := tx.Model(&models.Product{}).
+				Where("category_id = ?", category.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrInUse
+			}
+
+			if err := tx.Model(&models.Category{}).
+				Where("parent_id = ?", category.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrInUse
+			}
+		}
+
+		return tx.Delete(&category).Error
+	})
+}
+
+// RestoreCategory restores the most recently deleted category with the code. It fails with
+// ErrConflict if the code has been reused meanwhile and with ErrParentDeleted while its parent
+// is still deleted.
+func (r *Categories) RestoreCategory(code string) (*models.Category, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ? AND deleted_at IS NOT NULL", code).
+			Order("deleted_at DESC").
+			First(&category).Error; err != nil {
+			return translateError(err)
+		}
+
+		if category.ParentID != nil {
+			var parents int64
+			if err := tx.Model(&models.Category{}).Where("id = ?", *category.ParentID).Count(&parents).Error; err != nil {
+				return err
+			}
+			if parents == 0 {
+				return ErrParentDeleted
+			}
+		}
+
+		if err := tx.Unscoped().Model(&category).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return translateError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCategoryByCode(code)
+}
+
+// ancestorIDsSQL selects the id of the given category and of all its ancestors.
+const ancestorIDsSQL = `WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id FROM categories WHERE id = ?
+	UNION
+	SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+) SELECT id FROM ancestors`
+
+// resolveParent sets ParentID from the code of the category's Parent.
+func (r *Categories) resolveParent(category *models.Category) error {
+	if category.Parent == nil {
+		category.ParentID = nil
+		return nil
+	}
+
+	var parent models.Category
+	if err := r.db.Where("code = ?", category.Parent.Code).First(&parent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnknownCategory
+		}
+		return err
+	}
+
+	category.ParentID = &parent.ID
+	category.Parent = &parent
+	return nil
 }
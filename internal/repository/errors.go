@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned when a write would violate a uniqueness constraint.
+	ErrConflict = errors.New("record already exists")
+	// ErrInUse is returned when a record cannot be deleted because other records still reference it.
+	ErrInUse = errors.New("record is still referenced")
+	// ErrUnknownCategory is returned when a product or category references a category code that does not exist.
+	ErrUnknownCategory = errors.New("unknown category")
+	// ErrCategoryCycle is returned when a category would become its own ancestor.
+	ErrCategoryCycle = errors.New("category cannot be its own ancestor")
+	// ErrInvalidOptions is returned when a variant does not set exactly one value for each option of its product.
+	ErrInvalidOptions = errors.New("variant options do not match the product options")
+	// ErrDuplicateOptions is returned when another variant of the product has the same option values.
+	ErrDuplicateOptions = errors.New("another variant has the same option values")
+	// ErrInsufficientStock is returned when a stock decrement exceeds the quantity on hand and not reserved.
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrReservationClosed is returned when a reservation that is no longer active is confirmed or released.
+	ErrReservationClosed = errors.New("reservation is no longer active")
+	// ErrOptionsInUse is returned when the options of a product with variants would change.
+	ErrOptionsInUse = errors.New("product options cannot change while the product has variants")
+	// ErrInvalidTransition is returned when a product cannot move from its status to the requested one.
+	ErrInvalidTransition = errors.New("status transition is not allowed")
+	// ErrParentDeleted is returned when a category is restored while its parent is still deleted.
+	ErrParentDeleted = errors.New("parent category is deleted")
+	// ErrSaleOverlap is returned when a sale overlaps another sale of the same product or variant in the same currency.
+	ErrSaleOverlap = errors.New("sale overlaps another sale")
+	// ErrPriceInherited is returned when a price list entry is set for a variant that inherits the product price.
+	ErrPriceInherited = errors.New("variant inherits the product price")
+)
+
+// translateError maps gorm errors to the repository errors handlers rely on.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrConflict
+	default:
+		return err
+	}
+}
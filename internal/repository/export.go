@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// ExportRow is a product with one of its variants, or a product without variants, as exported.
+// Prices are list prices in the base currency; a variant without a price inherits the product price.
+// Category is the primary category, Categories the further ones the product is assigned to.
+type ExportRow struct {
+	Code         string
+	Name         string
+	Description  string
+	Brand        string
+	Price        decimal.Decimal
+	Category     string
+	Categories   []string
+	Status       string
+	SKU          string
+	VariantName  string
+	VariantPrice decimal.NullDecimal
+	// Options holds the variant's option values in the product's option order.
+	Options []models.VariantOption
+}
+
+// exportSQL selects a row per live variant, and a row for a product without variants. Deleted
+// products come with the variants deleted with them.
+const exportSQL = "products.code, products.name, products.description, products.brand, products.price," +
+	" c.code AS category, products.status, v.sku, v.name AS variant_name, v.price AS variant_price," +
+	" (SELECT json_agg(fc.code ORDER BY fc.code) FROM product_categories pc JOIN categories fc ON fc.id = pc.category_id" +
+	" WHERE pc.product_id = products.id AND pc.category_id IS DISTINCT FROM products.category_id) AS categories," +
+	" (SELECT json_agg(json_build_object('Name', po.name, 'Value', vo.value) ORDER BY po.position)" +
+	" FROM variant_option_values vo JOIN product_options po ON po.id = vo.option_id WHERE vo.variant_id = v.id) AS options"
+
+// exportRecord is a row of exportSQL as scanned.
+type exportRecord struct {
+	Code         string
+	Name         string
+	Description  string
+	Brand        string
+	Price        decimal.Decimal
+	Category     *string
+	Categories   *string
+	Status       string
+	SKU          *string
+	VariantName  *string
+	VariantPrice decimal.NullDecimal
+	Options      *string
+}
+
+// ExportProducts calls fn with every product and variant matching the filter, in the filter's
+// sort order with the variants of a product in creation order. Rows are read from a database
+// cursor one at a time, so memory use does not grow with the catalog. Pagination is ignored;
+// an error from fn stops the export and is returned.
+func (r *Products) ExportProducts(filter ProductsFilter, fn func(ExportRow) error) error {
+	query := applyFilters(r.db.Model(&models.Product{}), filter).
+		Select(exportSQL).
+		Joins("LEFT JOIN categories c ON c.id = products.category_id").
+		Joins("LEFT JOIN product_variants v ON v.product_id = products.id" +
+			" AND (v.deleted_at IS NULL OR v.deleted_at = products.deleted_at)")
+	query = orderBy(query, filter.Sort, productSortColumnsIn(priceCurrency(filter)), "products.id").Order("v.id")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	scanner := query.Session(&gorm.Session{NewDB: true})
+	for rows.Next() {
+		var record exportRecord
+		if err := scanner.ScanRows(rows, &record); err != nil {
+			return err
+		}
+
+		row := ExportRow{
+			Code:         record.Code,
+			Name:         record.Name,
+			Description:  record.Description,
+			Brand:        record.Brand,
+			Price:        record.Price,
+			Status:       record.Status,
+			VariantPrice: record.VariantPrice,
+		}
+		if record.Category != nil {
+			row.Category = *record.Category
+		}
+		if record.SKU != nil {
+			row.SKU = *record.SKU
+		}
+		if record.VariantName != nil {
+			row.VariantName = *record.VariantName
+		}
+		if record.Categories != nil {
+			if err := json.Unmarshal([]byte(*record.Categories), &row.Categories); err != nil {
+				return err
+			}
+		}
+		if record.Options != nil {
+			if err := json.Unmarshal([]byte(*record.Options), &row.Options); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetProductOptionNames returns the option names of the products matching the filter, each
+// once, in the order of their position within the products and then by name.
+func (r *Products) GetProductOptionNames(filter ProductsFilter) ([]string, error) {
+	products := applyFilters(r.db.Model(&models.Product{}), filter).Select("products.id")
+
+	var names []string
+	if err := r.db.Model(&models.ProductOption{}).
+		Where("product_id IN (?)", products).
+		Group("name").
+		Order("MIN(position), name").
+		Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
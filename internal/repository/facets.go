@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// Facet names accepted by GetProductFacets.
+const (
+	FacetCategory = "category"
+	FacetPrice    = "price"
+)
+
+// ProductFacetNames lists the facets product listings can be counted by.
+var ProductFacetNames = []string{FacetCategory, FacetPrice}
+
+// priceFacetBounds splits product prices into the buckets [0, 50), [50, 100), ... [500, ∞).
+var priceFacetBounds = []decimal.Decimal{
+	decimal.NewFromInt(50),
+	decimal.NewFromInt(100),
+	decimal.NewFromInt(250),
+	decimal.NewFromInt(500),
+}
+
+// ProductFacets holds the product counts of the requested facets.
+type ProductFacets struct {
+	Categories []CategoryFacet `json:"category,omitempty"`
+	Prices     []PriceFacet    `json:"price,omitempty"`
+}
+
+// CategoryFacet is the number of products assigned to a category.
+type CategoryFacet struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// PriceFacet is the number of products priced from Min up to, but not including, Max.
+// The last bucket has no Max.
+type PriceFacet struct {
+	Min   decimal.Decimal  `json:"min"`
+	Max   *decimal.Decimal `json:"max,omitempty"`
+	Count int64            `json:"count"`
+}
+
+// GetProductFacets counts the products matching the filter per category and per price bucket.
+// Each facet ignores its own filter, so the counts show what selecting another value would return.
+func (r *Products) GetProductFacets(filter ProductsFilter, facets []string) (*ProductFacets, error) {
+	result := &ProductFacets{}
+
+	for _, facet := range uniqueStrings(facets) {
+		var err error
+		switch facet {
+		case FacetCategory:
+			result.Categories, err = r.categoryFacet(filter)
+		case FacetPrice:
+			result.Prices, err = r.priceFacet(filter)
+		default:
+			err = fmt.Errorf("unknown facet %q", facet)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// categoryFacet counts the products per assigned category, most products first.
+func (r *Products) categoryFacet(filter ProductsFilter) ([]CategoryFacet, error) {
+	filter.CategoryCodes = nil
+
+	counts := []CategoryFacet{}
+	if err := applyFilters(r.db.Model(&models.Product{}), filter).
+		Joins("JOIN product_categories facet_pc ON facet_pc.product_id = products.id").
+		Joins("JOIN categories facet_c ON facet_c.id = facet_pc.category_id AND facet_c.deleted_at IS NULL").
+		Select("facet_c.code, facet_c.name, COUNT(DISTINCT products.id) AS count").
+		Group("facet_c.code, facet_c.name").
+		Order("count DESC").
+		Order("facet_c.code").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// priceFacet counts the products per bucket of their effective price, in the currency of the
+// filter, including empty buckets.
+func (r *Products) priceFacet(filter ProductsFilter) ([]PriceFacet, error) {
+	filter.MinPrice = nil
+	filter.MaxPrice = nil
+
+	bounds := make([]string, len(priceFacetBounds))
+	for i, bound := range priceFacetBounds {
+		bounds[i] = bound.String()
+	}
+
+	// width_bucket returns 0 below the first bound and len(bounds) from the last one on
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	if err := applyFilters(r.db.Model(&models.Product{}), filter).
+		Select("width_bucket(" + productEffectivePriceSQL(priceCurrency(filter)) + ", ARRAY[" + strings.Join(bounds, ",") + "]::numeric[]) AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	buckets := make([]PriceFacet, len(priceFacetBounds)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].Min = priceFacetBounds[i-1]
+		}
+		if i < len(priceFacetBounds) {
+			buckets[i].Max = &priceFacetBounds[i]
+		}
+	}
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < len(buckets) {
+			buckets[row.Bucket].Count = row.Count
+		}
+	}
+	return buckets, nil
+}
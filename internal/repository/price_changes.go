@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// GetPriceHistory returns the price changes of a product and its variants, newest first, or
+// ErrNotFound if the product does not exist in the scope.
+func (r *Products) GetPriceHistory(code string, scope ProductScope) ([]models.PriceChange, error) {
+	var product models.Product
+	if err := inScope(r.db.Where("code = ?", code), scope).Select("id").First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	changes := []models.PriceChange{}
+	if err := r.db.Where("product_id = ?", product.ID).
+		Order("changed_at DESC").
+		Order("id DESC").
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// recordProductPrice records a change of the product price from old, if it changed.
+func recordProductPrice(tx *gorm.DB, product *models.Product, old decimal.NullDecimal, actor string) error {
+	price := decimal.NewNullDecimal(product.Price)
+	if samePrice(old, price) {
+		return nil
+	}
+	return tx.Create(&models.PriceChange{
+		ProductID: product.ID,
+		Currency:  models.BaseCurrency,
+		OldPrice:  old,
+		NewPrice:  price,
+		Actor:     actor,
+	}).Error
+}
+
+// recordVariantPrice records a change of the variant's own price from old, if it changed.
+func recordVariantPrice(tx *gorm.DB, variant *models.Variant, old decimal.NullDecimal, actor string) error {
+	if samePrice(old, variant.Price) {
+		return nil
+	}
+	return tx.Create(&models.PriceChange{
+		ProductID: variant.ProductID,
+		VariantID: &variant.ID,
+		SKU:       variant.SKU,
+		Currency:  models.BaseCurrency,
+		OldPrice:  old,
+		NewPrice:  variant.Price,
+		Actor:     actor,
+	}).Error
+}
+
+// recordProductListPrice records a change of the product's price in a price list currency from
+// old, if it changed. A NULL price means the currency was removed from the price list.
+func recordProductListPrice(tx *gorm.DB, productID uint, currency string, old, price decimal.NullDecimal, actor string) error {
+	if samePrice(old, price) {
+		return nil
+	}
+	return tx.Create(&models.PriceChange{
+		ProductID: productID,
+		Currency:  currency,
+		OldPrice:  old,
+		NewPrice:  price,
+		Actor:     actor,
+	}).Error
+}
+
+// recordVariantListPrice records a change of the variant's price in a price list currency from
+// old, if it changed. A NULL price means the currency was removed from the price list.
+func recordVariantListPrice(tx *gorm.DB, variant *models.Variant, currency string, old, price decimal.NullDecimal, actor string) error {
+	if samePrice(old, price) {
+		return nil
+	}
+	return tx.Create(&models.PriceChange{
+		ProductID: variant.ProductID,
+		VariantID: &variant.ID,
+		SKU:       variant.SKU,
+		Currency:  currency,
+		OldPrice:  old,
+		NewPrice:  price,
+		Actor:     actor,
+	}).Error
+}
+
+func samePrice(a, b decimal.NullDecimal) bool {
+	if !a.Valid || !b.Valid {
+		return a.Valid == b.Valid
+	}
+	return a.Decimal.Equal(b.Decimal)
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"slices"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeSaleSQL is the condition of sale prices, aliased sp, that apply right now.
+const activeSaleSQL = "sp.starts_at <= NOW() AND sp.ends_at > NOW()"
+
+// priceCurrency returns the currency prices of the filter are in. Anything but a known
+// currency means the base currency, so the currency can be inlined into SQL safely.
+func priceCurrency(filter ProductsFilter) string {
+	if slices.Contains(models.Currencies, filter.Currency) {
+		return filter.Currency
+	}
+	return models.BaseCurrency
+}
+
+// productPriceSQL is the list price of a product in the currency; NULL when it has none.
+func productPriceSQL(currency string) string {
+	if currency == models.BaseCurrency {
+		return "products.price"
+	}
+	return "(SELECT pp.amount FROM product_prices pp WHERE pp.product_id = products.id AND pp.currency = '" + currency + "')"
+}
+
+// productEffectivePriceSQL is the price a product sells for right now in the currency: its
+// active sale price, or its list price.
+func productEffectivePriceSQL(currency string) string {
+	return "COALESCE(" + saleSQL("sp.product_id = products.id", currency) + ", " + productPriceSQL(currency) + ")"
+}
+
+// variantPriceSQL is the effective price of variant v in the currency: its active sale price,
+// its own list price, or the effective product price when it has no base price of its own.
+func variantPriceSQL(currency string) string {
+	list := "COALESCE(v.price, " + productEffectivePriceSQL(currency) + ")"
+	if currency != models.BaseCurrency {
+		list = "CASE WHEN v.price IS NULL THEN " + productEffectivePriceSQL(currency) +
+			" ELSE (SELECT vp.amount FROM variant_prices vp WHERE vp.variant_id = v.id AND vp.currency = '" + currency + "') END"
+	}
+	return "COALESCE(" + saleSQL("sp.variant_id = v.id", currency) + ", " + list + ")"
+}
+
+// onSaleSQL holds for products with an active sale on themselves or one of their variants.
+func onSaleSQL(currency string) string {
+	return "EXISTS (SELECT 1 FROM sale_prices sp LEFT JOIN product_variants v ON v.id = sp.variant_id" +
+		" WHERE (sp.product_id = products.id OR (v.product_id = products.id AND v.deleted_at IS NULL))" +
+		" AND sp.currency = '" + currency + "' AND " + activeSaleSQL + ")"
+}
+
+// saleSQL is the active sale price in the currency of the sale target matched by condition.
+func saleSQL(condition, currency string) string {
+	return "(SELECT sp.price FROM sale_prices sp WHERE " + condition + " AND sp.currency = '" + currency + "' AND " +
+		activeSaleSQL + " ORDER BY sp.starts_at DESC LIMIT 1)"
+}
+
+// productSortColumnsIn returns the product sort columns with prices effective in the currency.
+func productSortColumnsIn(currency string) map[string]string {
+	columns := make(map[string]string, len(productSortColumns))
+	for field, column := range productSortColumns {
+		columns[field] = column
+	}
+	columns["price"] = productEffectivePriceSQL(currency)
+	return columns
+}
+
+// activeSales preloads only the sale prices that apply right now, latest start first.
+func activeSales(db *gorm.DB) *gorm.DB {
+	return db.Where("starts_at <= NOW() AND ends_at > NOW()").Order("starts_at DESC")
+}
+
+// SetProductPrice sets the price of a product in a currency other than the base currency,
+// whose price is the product price itself. The change is recorded in the price history,
+// attributed to actor.
+func (r *Products) SetProductPrice(code, currency string, amount decimal.Decimal, actor string) (*models.ProductPrice, error) {
+	price := &models.ProductPrice{Currency: currency, Amount: amount}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the product so concurrent changes record the price each of them replaced
+		product, err := lockProduct(tx, code)
+		if err != nil {
+			return err
+		}
+		old, err := listPrice(tx, &models.ProductPrice{}, "product_id", product.ID, currency)
+		if err != nil {
+			return err
+		}
+
+		price.ProductID = product.ID
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "currency"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"amount": amount, "updated_at": gorm.Expr("NOW()")}),
+		}).Create(price).Error; err != nil {
+			return err
+		}
+		return recordProductListPrice(tx, product.ID, currency, old, decimal.NewNullDecimal(amount), actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// DeleteProductPrice removes the price of a product in a currency, so it is no longer sold in
+// it, and records the removal. It returns ErrNotFound if the product has no price in the currency.
+func (r *Products) DeleteProductPrice(code, currency, actor string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProduct(tx, code)
+		if err != nil {
+			return err
+		}
+		old, err := listPrice(tx, &models.ProductPrice{}, "product_id", product.ID, currency)
+		if err != nil {
+			return err
+		}
+		if !old.Valid {
+			return ErrNotFound
+		}
+
+		if err := tx.Where("product_id = ? AND currency = ?", product.ID, currency).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+		return recordProductListPrice(tx, product.ID, currency, old, decimal.NullDecimal{}, actor)
+	})
+}
+
+// SetVariantPrice sets the price of a variant in a currency other than the base currency. Only
+// variants with a base price of their own have price lists; the others inherit the product
+// price in every currency and fail with ErrPriceInherited. The change is recorded in the price
+// history, attributed to actor.
+func (r *Variants) SetVariantPrice(productCode, sku, currency string, amount decimal.Decimal, actor string) (*models.VariantPrice, error) {
+	price := &models.VariantPrice{Currency: currency, Amount: amount}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		variant, err := lockVariant(tx, productCode, sku)
+		if err != nil {
+			return err
+		}
+		if !variant.Price.Valid {
+			return ErrPriceInherited
+		}
+		old, err := listPrice(tx, &models.VariantPrice{}, "variant_id", variant.ID, currency)
+		if err != nil {
+			return err
+		}
+
+		price.VariantID = variant.ID
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "variant_id"}, {Name: "currency"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"amount": amount, "updated_at": gorm.Expr("NOW()")}),
+		}).Create(price).Error; err != nil {
+			return err
+		}
+		return recordVariantListPrice(tx, variant, currency, old, decimal.NewNullDecimal(amount), actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// DeleteVariantPrice removes the price of a variant in a currency and records the removal. It
+// returns ErrNotFound if the variant has no price in the currency.
+func (r *Variants) DeleteVariantPrice(productCode, sku, currency, actor string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		variant, err := lockVariant(tx, productCode, sku)
+		if err != nil {
+			return err
+		}
+		old, err := listPrice(tx, &models.VariantPrice{}, "variant_id", variant.ID, currency)
+		if err != nil {
+			return err
+		}
+		if !old.Valid {
+			return ErrNotFound
+		}
+
+		if err := tx.Where("variant_id = ? AND currency = ?", variant.ID, currency).Delete(&models.VariantPrice{}).Error; err != nil {
+			return err
+		}
+		return recordVariantListPrice(tx, variant, currency, old, decimal.NullDecimal{}, actor)
+	})
+}
+
+// lockProduct locks a live product for a change of its price lists.
+func lockProduct(tx *gorm.DB, code string) (*models.Product, error) {
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		Select("id").
+		First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &product, nil
+}
+
+// lockVariant locks a live variant of a live product for a change of its price lists.
+func lockVariant(tx *gorm.DB, productCode, sku string) (*models.Variant, error) {
+	var variant models.Variant
+	if err := tx.Model(&models.Variant{}).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "product_variants"}}).
+		Joins("JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL").
+		Where("products.code = ? AND product_variants.sku = ?", productCode, sku).
+		Select("product_variants.id", "product_variants.product_id", "product_variants.sku", "product_variants.price").
+		First(&variant).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &variant, nil
+}
+
+// listPrice reads the price list entry of the product or variant with the id in the currency;
+// NULL when there is none.
+func listPrice(tx *gorm.DB, model interface{}, column string, id uint, currency string) (decimal.NullDecimal, error) {
+	var amounts []decimal.Decimal
+	if err := tx.Model(model).
+		Where(column+" = ? AND currency = ?", id, currency).
+		Pluck("amount", &amounts).Error; err != nil {
+		return decimal.NullDecimal{}, err
+	}
+	if len(amounts) == 0 {
+		return decimal.NullDecimal{}, nil
+	}
+	return decimal.NewNullDecimal(amounts[0]), nil
+}
@@ -1,25 +1,136 @@
 package repository
 
 import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
 	"github.com/mytheresa/go-hiring-challenge/internal/database"
 	"github.com/mytheresa/go-hiring-challenge/models"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductsInterface interface {
 	GetProducts(filter ProductsFilter) ([]models.Product, int64, error)
-	GetProductByCode(code string) (*models.Product, error)
+	GetProductByCode(code string, scope ProductScope) (*models.Product, error)
+	SearchProducts(terms string, filter ProductsFilter) ([]SearchResult, int64, error)
+	GetProductFacets(filter ProductsFilter, facets []string) (*ProductFacets, error)
+	GetProductOptionNames(filter ProductsFilter) ([]string, error)
+	ExportProducts(filter ProductsFilter, fn func(ExportRow) error) error
+	CreateProduct(product *models.Product, actor string) error
+	UpdateProduct(product *models.Product, actor string) error
+	ChangeProductStatus(code, status string, publishAt, unpublishAt *time.Time) (*models.Product, error)
+	GetPriceHistory(code string, scope ProductScope) ([]models.PriceChange, error)
+	SetProductPrice(code, currency string, amount decimal.Decimal, actor string) (*models.ProductPrice, error)
+	DeleteProductPrice(code, currency, actor string) error
+	DeleteProduct(code string) error
+	RestoreProduct(code string) (*models.Product, error)
+	AttachCategories(code string, categoryCodes []string) error
+	DetachCategory(code, categoryCode string) error
 }
 
 type Products struct {
 	db database.Database
 }
 
+// Category match modes for ProductsFilter.CategoryMatch.
+const (
+	// CategoryMatchAny keeps products assigned to at least one of the categories.
+	CategoryMatchAny = "any"
+	// CategoryMatchAll keeps products assigned to every one of the categories.
+	CategoryMatchAll = "all"
+)
+
+// ProductSortFields lists the fields products can be sorted by.
+var ProductSortFields = []string{"code", "price", "createdAt"}
+
+var productSortColumns = map[string]string{
+	"code":      "products.code",
+	"price":     "products.price",
+	"createdAt": "products.created_at",
+}
+
+// productSortValues reads the value of each sort field from a product, for cursors.
+var productSortValues = map[string]func(*models.Product) string{
+	"code":      func(p *models.Product) string { return p.Code },
+	"price":     func(p *models.Product) string { return p.Price.String() },
+	"createdAt": func(p *models.Product) string { return p.CreatedAt.Format(cursorTimeLayout) },
+}
+
+// cursorTimeLayout keeps the full precision of timestamp columns in cursors.
+const cursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+// ProductCursor returns the cursor that continues a listing sorted by sort after the given product.
+func ProductCursor(product models.Product, sort []common.SortField) common.Cursor {
+	cursor := common.Cursor{
+		Sort:   common.SortKey(sort),
+		Values: make([]string, len(sort)),
+		ID:     product.ID,
+	}
+	for i, field := range sort {
+		cursor.Values[i] = productSortValues[field.Name](&product)
+	}
+	return cursor
+}
+
+// ProductScope selects the products catalog queries see.
+type ProductScope int
+
+const (
+	// PublishedProducts are the products the storefront shows right now.
+	PublishedProducts ProductScope = iota
+	// AllProducts includes drafts, archived and scheduled products, for admin use.
+	AllProducts
+	// AllProductsWithDeleted also includes deleted products.
+	AllProductsWithDeleted
+)
+
+// publishedSQL holds for published products within their publication window.
+const publishedSQL = "products.status = 'published'" +
+	" AND (products.publish_at IS NULL OR products.publish_at <= NOW())" +
+	" AND (products.unpublish_at IS NULL OR products.unpublish_at > NOW())"
+
 type ProductsFilter struct {
-	CategoryCode *string
-	MaxPrice     *decimal.Decimal
-	Offset       int
-	Limit        int
+	CategoryCodes []string
+	CategoryMatch string
+	// IncludeSubcategories widens the category filter to every descendant of the categories.
+	IncludeSubcategories bool
+	// CodePrefix keeps products whose code starts with the prefix.
+	CodePrefix *string
+	// MinPrice and MaxPrice bound the product price effective right now, inclusively.
+	MinPrice *decimal.Decimal
+	MaxPrice *decimal.Decimal
+	// VariantMinPrice and VariantMaxPrice keep products with at least one variant whose
+	// effective price is within the bounds, inclusively.
+	VariantMinPrice *decimal.Decimal
+	VariantMaxPrice *decimal.Decimal
+	// Options keeps products with a variant that takes one of the given values for every
+	// option, e.g. {"size": {"M", "L"}}. Names and values match case-insensitively.
+	Options     map[string][]string
+	HasVariants *bool
+	// InStock keeps products with (true) or without (false) a variant that can be sold.
+	InStock *bool
+	// OnSale keeps products with (true) or without (false) an active sale on the product or a variant.
+	OnSale *bool
+	// Scope is PublishedProducts unless admin queries ask for AllProducts or AllProductsWithDeleted.
+	Scope ProductScope
+	// Statuses keeps products in one of the statuses.
+	Statuses []string
+	// Sort orders the products; the id is always the last key.
+	Sort []common.SortField
+	// After switches to keyset pagination: only products after the cursor are returned and Offset is ignored.
+	After  *common.Cursor
+	Offset int
+	Limit  int
+	// SkipTotal skips counting all matching products; the returned total is then 0.
+	SkipTotal bool
+	// Currency is the currency price filters and sorting use; only products priced in it are
+	// returned. Empty means the base currency.
+	Currency string
 }
 
 func NewProducts(db database.Database) *Products {
@@ -32,43 +143,579 @@ func (r *Products) GetProducts(filter ProductsFilter) ([]models.Product, int64,
 	var products []models.Product
 	var total int64
 
-	query := r.db.Model(&models.Product{})
+	query := applyFilters(r.db.Model(&models.Product{}), filter)
 
-	// Apply category filter
-	if filter.CategoryCode != nil {
-		query = query.Joins("JOIN categories ON categories.id = products.category_id").
-			Where("categories.code = ?", *filter.CategoryCode)
+	// Get total count
+	if !filter.SkipTotal {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 	}
 
-	// Apply max price filter
-	if filter.MaxPrice != nil {
-		query = query.Where("products.price <= ?", *filter.MaxPrice)
+	// Apply keyset or offset pagination
+	sortColumns := productSortColumnsIn(priceCurrency(filter))
+	if filter.After != nil {
+		condition, args := keysetCondition(filter.Sort, sortColumns, "products.id", *filter.After)
+		query = query.Where(condition, args...)
+	} else {
+		query = query.Offset(filter.Offset)
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
+	// Apply sort order, limit and preload relations
+	if err := withRelations(orderBy(query, filter.Sort, sortColumns, "products.id")).
+		Limit(filter.Limit).
+		Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination and preload relations
-	if err := query.
-		Preload("Category").
-		Preload("Variants").
-		Offset(filter.Offset).
-		Limit(filter.Limit).
-		Find(&products).Error; err != nil {
+	if err := r.attachBreadcrumbs(pointers(products)...); err != nil {
+		return nil, 0, err
+	}
+	if err := attachProductAvailability(r.db, pointers(products)...); err != nil {
 		return nil, 0, err
 	}
 
 	return products, total, nil
 }
-func (r *Products) GetProductByCode(code string) (*models.Product, error) {
+
+// GetProductByCode returns the product with the code if the scope includes it, or ErrNotFound.
+// When the scope includes deleted products, a product that is not deleted comes first, then the
+// most recently deleted one.
+func (r *Products) GetProductByCode(code string, scope ProductScope) (*models.Product, error) {
 	var product models.Product
-	if err := r.db.Where("code = ?", code).
-		Preload("Category").
-		Preload("Variants").
+	if err := withRelations(inScope(r.db.Where("code = ?", code), scope)).
+		Order("products.deleted_at DESC NULLS FIRST").
 		First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := r.attachBreadcrumbs(&product); err != nil {
+		return nil, err
+	}
+	if err := attachProductAvailability(r.db, &product); err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
+
+// CreateProduct inserts a new product. The primary category, if any, is looked up by its code
+// and also recorded as a category assignment. The initial price starts the price history.
+func (r *Products) CreateProduct(product *models.Product, actor string) error {
+	if err := r.resolveCategory(product); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(product).Omit(clause.Associations).Create(product).Error; err != nil {
+			return translateError(err)
+		}
+		if err := recordProductPrice(tx, product, decimal.NullDecimal{}, actor); err != nil {
+			return err
+		}
+		if err := replaceImages(tx, product); err != nil {
+			return err
+		}
+		if err := replaceOptions(tx, product); err != nil {
+			return err
+		}
+		return assignPrimaryCategory(tx, product, nil)
+	})
+}
+
+// UpdateProduct persists all fields of an existing product, replacing its images and options.
+// Options can only change while the product has no variants. The primary category, if any, is
+// looked up by its code. Changing it moves the matching category assignment along. A price
+// change is recorded in the price history, attributed to actor.
+func (r *Products) UpdateProduct(product *models.Product, actor string) error {
+	if err := r.resolveCategory(product); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so concurrent updates record the price each of them replaced
+		var current models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "category_id", "price").
+			First(&current, product.ID).Error; err != nil {
+			return translateError(err)
+		}
+
+		if err := tx.Model(product).Omit(clause.Associations).Save(product).Error; err != nil {
+			return translateError(err)
+		}
+		if err := recordProductPrice(tx, product, decimal.NewNullDecimal(current.Price), actor); err != nil {
+			return err
+		}
+		if err := replaceImages(tx, product); err != nil {
+			return err
+		}
+		if err := replaceOptions(tx, product); err != nil {
+			return err
+		}
+		if err := assignPrimaryCategory(tx, product, current.CategoryID); err != nil {
+			return err
+		}
+		return attachProductAvailability(tx, product)
+	})
+}
+
+// ChangeProductStatus moves a product to status and sets its publication window, refusing
+// transitions the workflow does not allow with ErrInvalidTransition.
+func (r *Products) ChangeProductStatus(code, status string, publishAt, unpublishAt *time.Time) (*models.Product, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).
+			Select("id", "status").
+			First(&current).Error; err != nil {
+			return translateError(err)
+		}
+		if !models.CanTransition(current.Status, status) {
+			return ErrInvalidTransition
+		}
+
+		return tx.Model(&current).Updates(map[string]interface{}{
+			"status":       status,
+			"publish_at":   publishAt,
+			"unpublish_at": unpublishAt,
+			"updated_at":   gorm.Expr("NOW()"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetProductByCode(code, AllProducts)
+}
+
+// DeleteProduct soft deletes a product together with its variants. Both get the same deletion
+// time, so RestoreProduct can tell them from variants deleted before.
+func (r *Products) DeleteProduct(code string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).
+			Select("id").
+			First(&product).Error; err != nil {
+			return translateError(err)
+		}
+
+		// NOW() is the transaction start time, the same for both statements
+		if err := tx.Model(&models.Variant{}).
+			Where("product_id = ?", product.ID).
+			UpdateColumn("deleted_at", gorm.Expr("NOW()")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&product).UpdateColumn("deleted_at", gorm.Expr("NOW()")).Error
+	})
+}
+
+// RestoreProduct restores the most recently deleted product with the code along with the variants
+// deleted with it. It fails with ErrConflict if the code or a SKU has been reused meanwhile.
+func (r *Products) RestoreProduct(code string) (*models.Product, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ? AND deleted_at IS NOT NULL", code).
+			Select("id", "deleted_at").
+			Order("deleted_at DESC").
+			First(&product).Error; err != nil {
+			return translateError(err)
+		}
+
+		if err := tx.Unscoped().Model(&product).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return translateError(err)
+		}
+		if err := tx.Unscoped().Model(&models.Variant{}).
+			Where("product_id = ? AND deleted_at = ?", product.ID, product.DeletedAt).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return translateError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetProductByCode(code, AllProducts)
+}
+
+// AttachCategories assigns the product to the given categories. Existing assignments are kept.
+func (r *Products) AttachCategories(code string, categoryCodes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Where("code = ?", code).Select("id").First(&product).Error; err != nil {
+			return translateError(err)
+		}
+
+		var categories []models.Category
+		if err := tx.Where("code IN ?", categoryCodes).Find(&categories).Error; err != nil {
+			return err
+		}
+		if len(categories) != len(uniqueStrings(categoryCodes)) {
+			return ErrUnknownCategory
+		}
+
+		for _, category := range categories {
+			if err := tx.Exec(
+				"INSERT INTO product_categories (product_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+				product.ID, category.ID,
+			).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DetachCategory removes a category assignment. Detaching the primary category also clears it.
+func (r *Products) DetachCategory(code, categoryCode string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Where("code = ?", code).Select("id", "category_id").First(&product).Error; err != nil {
+			return translateError(err)
+		}
+
+		result := tx.Exec(
+			"DELETE FROM product_categories WHERE product_id = ? AND category_id = (SELECT id FROM categories WHERE code = ? AND deleted_at IS NULL)",
+			product.ID, categoryCode,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Model(&product).
+			Where("category_id = (SELECT id FROM categories WHERE code = ? AND deleted_at IS NULL)", categoryCode).
+			UpdateColumn("category_id", nil).Error
+	})
+}
+
+// replaceImages stores the product's images in their current order, replacing any previous ones.
+func replaceImages(tx *gorm.DB, product *models.Product) error {
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductImage{}).Error; err != nil {
+		return err
+	}
+	if len(product.Images) == 0 {
+		return nil
+	}
+
+	for i := range product.Images {
+		product.Images[i].ID = 0
+		product.Images[i].ProductID = product.ID
+		product.Images[i].Position = i
+	}
+	return tx.Create(&product.Images).Error
+}
+
+// replaceOptions stores the product's options in their current order. Options that did not
+// change are kept, as variant option values reference them.
+func replaceOptions(tx *gorm.DB, product *models.Product) error {
+	var current []models.ProductOption
+	if err := tx.Where("product_id = ?", product.ID).Order("position").Find(&current).Error; err != nil {
+		return err
+	}
+	if sameOptions(current, product.Options) {
+		product.Options = current
+		return nil
+	}
+
+	var variants int64
+	if err := tx.Model(&models.Variant{}).Where("product_id = ?", product.ID).Count(&variants).Error; err != nil {
+		return err
+	}
+	if variants > 0 {
+		return ErrOptionsInUse
+	}
+
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductOption{}).Error; err != nil {
+		return err
+	}
+	if len(product.Options) == 0 {
+		return nil
+	}
+
+	for i := range product.Options {
+		product.Options[i].ID = 0
+		product.Options[i].ProductID = product.ID
+		product.Options[i].Position = i
+	}
+	return tx.Create(&product.Options).Error
+}
+
+// sameOptions reports whether both lists have the same option names in the same order.
+func sameOptions(a, b []models.ProductOption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// withRelations preloads everything a product response shows, images and options in their
+// display order, and the price lists and active sales ApplyCurrency needs.
+func withRelations(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Category").
+		Preload("Categories").
+		Preload("Prices").
+		Preload("Sales", activeSales).
+		Preload("Options", byPosition).
+		Preload("Variants").
+		Preload("Variants.Prices").
+		Preload("Variants.Sales", activeSales).
+		Preload("Variants.Options", withOptionNames).
+		Preload("Images", byPosition)
+}
+
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// assignPrimaryCategory keeps the category assignments in line with the primary category,
+// replacing the assignment of the previous primary category if it changed.
+func assignPrimaryCategory(tx *gorm.DB, product *models.Product, previousID *uint) error {
+	if previousID != nil && (product.CategoryID == nil || *previousID != *product.CategoryID) {
+		if err := tx.Exec(
+			"DELETE FROM product_categories WHERE product_id = ? AND category_id = ?",
+			product.ID, *previousID,
+		).Error; err != nil {
+			return err
+		}
+	}
+
+	if product.CategoryID == nil {
+		return nil
+	}
+	return tx.Exec(
+		"INSERT INTO product_categories (product_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		product.ID, *product.CategoryID,
+	).Error
+}
+
+// resolveCategory sets CategoryID from the code of the product's Category.
+func (r *Products) resolveCategory(product *models.Product) error {
+	if product.Category == nil {
+		product.CategoryID = nil
+		return nil
+	}
+
+	var category models.Category
+	if err := r.db.Where("code = ?", product.Category.Code).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnknownCategory
+		}
+		return err
+	}
+
+	product.CategoryID = &category.ID
+	product.Category = &category
+	return nil
+}
+
+// applyFilters narrows a products query down to the products matching the filter.
+func applyFilters(query *gorm.DB, filter ProductsFilter) *gorm.DB {
+	query = inScope(query, filter.Scope)
+
+	// Apply category filter
+	if len(filter.CategoryCodes) > 0 {
+		if filter.CategoryMatch == CategoryMatchAll {
+			for _, code := range filter.CategoryCodes {
+				query = query.Where(inCategoriesSQL(filter.IncludeSubcategories), []string{code})
+			}
+		} else {
+			query = query.Where(inCategoriesSQL(filter.IncludeSubcategories), filter.CategoryCodes)
+		}
+	}
+
+	// Apply status filter
+	if len(filter.Statuses) > 0 {
+		query = query.Where("products.status IN ?", filter.Statuses)
+	}
+
+	// Apply code prefix filter
+	if filter.CodePrefix != nil {
+		query = query.Where(`products.code LIKE ? ESCAPE '\'`, escapeLike(*filter.CodePrefix)+"%")
+	}
+
+	// Keep products sold in the currency, with every variant that has a price of its own priced
+	// in it too, like ApplyCurrency requires; price filters compare prices in that currency
+	currency := priceCurrency(filter)
+	if currency != models.BaseCurrency {
+		query = query.Where(productPriceSQL(currency)+" IS NOT NULL").
+			Where("NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id AND v.deleted_at IS NULL"+
+				" AND v.price IS NOT NULL AND NOT EXISTS (SELECT 1 FROM variant_prices vp WHERE vp.variant_id = v.id AND vp.currency = ?))", currency)
+	}
+
+	// Apply price range filter on the price effective right now, sales included
+	price := productEffectivePriceSQL(currency)
+	if filter.MinPrice != nil {
+		query = query.Where(price+" >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(price+" <= ?", *filter.MaxPrice)
+	}
+
+	// Apply variant effective price filter; both bounds must hold for the same variant
+	if filter.VariantMinPrice != nil || filter.VariantMaxPrice != nil {
+		variants := "SELECT 1 FROM product_variants v WHERE v.product_id = products.id AND v.deleted_at IS NULL"
+		var args []interface{}
+		if filter.VariantMinPrice != nil {
+			variants += " AND " + variantPriceSQL(currency) + " >= ?"
+			args = append(args, *filter.VariantMinPrice)
+		}
+		if filter.VariantMaxPrice != nil {
+			variants += " AND " + variantPriceSQL(currency) + " <= ?"
+			args = append(args, *filter.VariantMaxPrice)
+		}
+		query = query.Where("EXISTS ("+variants+")", args...)
+	}
+
+	// Apply variant option filter; a single variant must match every option
+	if len(filter.Options) > 0 {
+		names := make([]string, 0, len(filter.Options))
+		for name := range filter.Options {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		variants := "SELECT 1 FROM product_variants v WHERE v.product_id = products.id AND v.deleted_at IS NULL"
+		var args []interface{}
+		for _, name := range names {
+			values := make([]string, len(filter.Options[name]))
+			for i, value := range filter.Options[name] {
+				values[i] = strings.ToLower(value)
+			}
+			variants += " AND EXISTS (SELECT 1 FROM variant_option_values vo JOIN product_options po ON po.id = vo.option_id" +
+				" WHERE vo.variant_id = v.id AND lower(po.name) = ? AND lower(vo.value) IN ?)"
+			args = append(args, strings.ToLower(name), values)
+		}
+		query = query.Where("EXISTS ("+variants+")", args...)
+	}
+
+	// Apply availability filter
+	if filter.InStock != nil {
+		inStock := "EXISTS (SELECT 1 FROM product_variants v JOIN variant_availability a ON a.variant_id = v.id" +
+			" WHERE v.product_id = products.id AND v.deleted_at IS NULL AND a.available > 0)"
+		if !*filter.InStock {
+			inStock = "NOT " + inStock
+		}
+		query = query.Where(inStock)
+	}
+
+	// Apply sale filter
+	if filter.OnSale != nil {
+		onSale := onSaleSQL(currency)
+		if !*filter.OnSale {
+			onSale = "NOT " + onSale
+		}
+		query = query.Where(onSale)
+	}
+
+	// Apply variants presence filter
+	if filter.HasVariants != nil {
+		hasVariants := "EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id AND v.deleted_at IS NULL)"
+		if !*filter.HasVariants {
+			hasVariants = "NOT " + hasVariants
+		}
+		query = query.Where(hasVariants)
+	}
+
+	return query
+}
+
+// inScope restricts a products query to the products the scope sees.
+func inScope(query *gorm.DB, scope ProductScope) *gorm.DB {
+	switch scope {
+	case AllProducts:
+		return query
+	case AllProductsWithDeleted:
+		return query.Unscoped()
+	default:
+		return query.Where(publishedSQL)
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// inCategoriesSQL matches products assigned to any of the categories with the given codes,
+// or to any of their descendants when includeSubcategories is set.
+func inCategoriesSQL(includeSubcategories bool) string {
+	categoryIDs := "SELECT id FROM categories WHERE code IN ? AND deleted_at IS NULL"
+	if includeSubcategories {
+		categoryIDs = subtreeSQL
+	}
+	return "EXISTS (SELECT 1 FROM product_categories pc WHERE pc.product_id = products.id AND pc.category_id IN (" + categoryIDs + "))"
+}
+
+// subtreeSQL selects the ids of the categories with the given codes and all of their descendants.
+const subtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE code IN ? AND deleted_at IS NULL
+	UNION
+	SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.deleted_at IS NULL
+) SELECT id FROM subtree`
+
+// ancestorsSQL selects the given categories and all of their ancestors.
+const ancestorsSQL = `WITH RECURSIVE ancestors AS (
+	SELECT id, code, name, parent_id FROM categories WHERE id IN ?
+	UNION
+	SELECT c.id, c.code, c.name, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+) SELECT * FROM ancestors`
+
+// attachBreadcrumbs sets the category path of each product, loading all ancestors in one query.
+func (r *Products) attachBreadcrumbs(products ...*models.Product) error {
+	var ids []uint
+	for _, product := range products {
+		if product.CategoryID != nil {
+			ids = append(ids, *product.CategoryID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var ancestors []models.Category
+	if err := r.db.Raw(ancestorsSQL, ids).Scan(&ancestors).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[uint]models.Category, len(ancestors))
+	for _, category := range ancestors {
+		byID[category.ID] = category
+	}
+
+	for _, product := range products {
+		if product.CategoryID != nil {
+			product.Breadcrumbs = models.CategoryPath(*product.CategoryID, byID)
+		}
+	}
+	return nil
+}
+
+func pointers[T any](values []T) []*T {
+	result := make([]*T, len(values))
+	for i := range values {
+		result[i] = &values[i]
+	}
+	return result
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
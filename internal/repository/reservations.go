@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationsInterface interface {
+	CreateReservation(sku, warehouse string, quantity int, ttl time.Duration) (*models.Reservation, error)
+	GetReservation(id uint) (*models.Reservation, error)
+	ConfirmReservation(id uint) (*models.Reservation, error)
+	ReleaseReservation(id uint) (*models.Reservation, error)
+	ExpireReservations() (int64, error)
+}
+
+type Reservations struct {
+	db database.Database
+}
+
+func NewReservations(db database.Database) *Reservations {
+	return &Reservations{
+		db: db,
+	}
+}
+
+// CreateReservation holds quantity of a variant in a warehouse for ttl. It fails with
+// ErrInsufficientStock unless the quantity is available there. The stock row is locked while
+// checking, so concurrent reservations cannot hold more than the stock on hand.
+func (r *Reservations) CreateReservation(sku, warehouse string, quantity int, ttl time.Duration) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.Variant
+		if err := tx.Where("sku = ?", sku).Select("id", "sku").First(&variant).Error; err != nil {
+			return translateError(err)
+		}
+
+		var stock models.VariantStock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("variant_id = ? AND warehouse = ?", variant.ID, warehouse).
+			First(&stock).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInsufficientStock
+			}
+			return err
+		}
+
+		reserved, err := activeReserved(tx, variant.ID, warehouse)
+		if err != nil {
+			return err
+		}
+		if int64(stock.Quantity)-reserved < int64(quantity) {
+			return ErrInsufficientStock
+		}
+
+		// Expiry is computed by the database, whose clock the sweeper and availability use too
+		if err := tx.Raw(
+			`INSERT INTO stock_reservations (variant_id, warehouse, quantity, status, expires_at)
+			VALUES (?, ?, ?, ?, NOW() + make_interval(secs => ?))
+			RETURNING *`,
+			variant.ID, warehouse, quantity, models.ReservationActive, ttl.Seconds(),
+		).Scan(&reservation).Error; err != nil {
+			return err
+		}
+
+		reservation.SKU = variant.SKU
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+func (r *Reservations) GetReservation(id uint) (*models.Reservation, error) {
+	var reservation models.Reservation
+	if err := withSKU(r.db.Model(&models.Reservation{})).
+		Where("stock_reservations.id = ?", id).
+		First(&reservation).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &reservation, nil
+}
+
+// ConfirmReservation completes an active reservation, taking its quantity out of stock.
+func (r *Reservations) ConfirmReservation(id uint) (*models.Reservation, error) {
+	return r.close(id, models.ReservationConfirmed, func(tx *gorm.DB, reservation *models.Reservation) error {
+		result := tx.Exec(
+			`UPDATE variant_stock SET quantity = quantity - ?, updated_at = NOW()
+			WHERE variant_id = ? AND warehouse = ? AND quantity >= ?`,
+			reservation.Quantity, reservation.VariantID, reservation.Warehouse, reservation.Quantity,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+		return nil
+	})
+}
+
+// ReleaseReservation cancels an active reservation, freeing its quantity.
+func (r *Reservations) ReleaseReservation(id uint) (*models.Reservation, error) {
+	return r.close(id, models.ReservationReleased, nil)
+}
+
+// ExpireReservations marks active reservations past their expiry as expired and returns how many were.
+func (r *Reservations) ExpireReservations() (int64, error) {
+	result := r.db.Model(&models.Reservation{}).
+		Where("status = ? AND expires_at <= NOW()", models.ReservationActive).
+		Updates(map[string]interface{}{"status": models.ReservationExpired, "updated_at": gorm.Expr("NOW()")})
+	return result.RowsAffected, result.Error
+}
+
+// close moves an active, unexpired reservation to status, running apply first in the same
+// transaction. The reservation row is locked so it is closed only once.
+func (r *Reservations) close(id uint, status string, apply func(tx *gorm.DB, reservation *models.Reservation) error) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := withSKU(tx.Model(&models.Reservation{})).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "stock_reservations"}}).
+			Where("stock_reservations.id = ? AND stock_reservations.status = ? AND stock_reservations.expires_at > NOW()",
+				id, models.ReservationActive).
+			First(&reservation).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			// Tell a missing reservation from one that was closed or expired
+			var exists int64
+			if err := tx.Model(&models.Reservation{}).Where("id = ?", id).Count(&exists).Error; err != nil {
+				return err
+			}
+			if exists == 0 {
+				return ErrNotFound
+			}
+			return ErrReservationClosed
+		}
+
+		if apply != nil {
+			if err := apply(tx, &reservation); err != nil {
+				return err
+			}
+		}
+
+		reservation.Status = status
+		return tx.Model(&reservation).Update("status", status).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// activeReserved is the quantity of a variant held in a warehouse by reservations that are
+// active and not yet expired.
+func activeReserved(tx *gorm.DB, variantID uint, warehouse string) (int64, error) {
+	var reserved int64
+	err := tx.Model(&models.Reservation{}).
+		Where("variant_id = ? AND warehouse = ? AND status = ? AND expires_at > NOW()",
+			variantID, warehouse, models.ReservationActive).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&reserved).Error
+	return reserved, err
+}
+
+// withSKU selects reservations together with the SKU of their variant.
+func withSKU(query *gorm.DB) *gorm.DB {
+	return query.
+		Select("stock_reservations.*, product_variants.sku").
+		Joins("JOIN product_variants ON product_variants.id = stock_reservations.variant_id")
+}
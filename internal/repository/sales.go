@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SalesInterface interface {
+	GetSales(productCode string, scope ProductScope) ([]models.SalePrice, error)
+	CreateSale(productCode string, sale *models.SalePrice) error
+	DeleteSale(productCode string, id uint) error
+}
+
+type Sales struct {
+	db database.Database
+}
+
+func NewSales(db database.Database) *Sales {
+	return &Sales{
+		db: db,
+	}
+}
+
+// GetSales returns the sales of a product and its variants that have not ended yet, in the
+// order they start, or ErrNotFound if the product does not exist in the scope.
+func (r *Sales) GetSales(productCode string, scope ProductScope) ([]models.SalePrice, error) {
+	var product models.Product
+	if err := inScope(r.db.Where("code = ?", productCode), scope).Select("id").First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	sales := []models.SalePrice{}
+	if err := withVariantSKU(r.db.Model(&models.SalePrice{})).
+		Where("(sale_prices.product_id = ? OR product_variants.product_id = ?) AND sale_prices.ends_at > NOW()", product.ID, product.ID).
+		Order("sale_prices.starts_at").
+		Order("sale_prices.id").
+		Find(&sales).Error; err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// CreateSale schedules a sale of the product, or of its variant with sale.SKU when set. Sales of
+// the same product or variant in the same currency must not overlap; the product row is locked
+// while checking so concurrent requests cannot both pass.
+func (r *Sales) CreateSale(productCode string, sale *models.SalePrice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", productCode).
+			Select("id").
+			First(&product).Error; err != nil {
+			return translateError(err)
+		}
+
+		overlapping := tx.Model(&models.SalePrice{}).
+			Where("currency = ? AND starts_at < ? AND ends_at > ?", sale.Currency, sale.EndsAt, sale.StartsAt)
+		if sale.SKU != "" {
+			var variant models.Variant
+			if err := tx.Where("product_id = ? AND sku = ?", product.ID, sale.SKU).
+				Select("id").
+				First(&variant).Error; err != nil {
+				return translateError(err)
+			}
+			sale.ProductID, sale.VariantID = nil, &variant.ID
+			overlapping = overlapping.Where("variant_id = ?", variant.ID)
+		} else {
+			sale.ProductID, sale.VariantID = &product.ID, nil
+			overlapping = overlapping.Where("product_id = ?", product.ID)
+		}
+
+		var count int64
+		if err := overlapping.Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSaleOverlap
+		}
+
+		return tx.Create(sale).Error
+	})
+}
+
+// DeleteSale removes a sale of the product or one of its variants, ending it at once if it is active.
+func (r *Sales) DeleteSale(productCode string, id uint) error {
+	result := r.db.Exec(
+		`DELETE FROM sale_prices sp
+		WHERE sp.id = ? AND EXISTS (
+			SELECT 1 FROM products p LEFT JOIN product_variants v ON v.product_id = p.id AND v.deleted_at IS NULL
+			WHERE p.code = ? AND p.deleted_at IS NULL AND (sp.product_id = p.id OR sp.variant_id = v.id)
+		)`,
+		id, productCode,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// withVariantSKU selects sale prices together with the SKU of their variant, if any.
+func withVariantSKU(query *gorm.DB) *gorm.DB {
+	return query.
+		Select("sale_prices.*, product_variants.sku").
+		Joins("LEFT JOIN product_variants ON product_variants.id = sale_prices.variant_id AND product_variants.deleted_at IS NULL")
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// SearchResult is a product matching a search, with its relevance and the matching text highlighted.
+type SearchResult struct {
+	models.Product
+	Rank      float64
+	Highlight string
+}
+
+// searchHit is the ranked row of a search, before the products are loaded.
+type searchHit struct {
+	ID        uint
+	Rank      float64
+	Highlight string
+}
+
+// SearchProducts returns the products matching the search terms, best matches first.
+// Every term must match the start of a word in the product code, name, brand, description,
+// variant names or SKUs.
+// The filter narrows the results further; only its offset pagination is used.
+func (r *Products) SearchProducts(terms string, filter ProductsFilter) ([]SearchResult, int64, error) {
+	tsquery := prefixQuery(terms)
+	if tsquery == "" {
+		return []SearchResult{}, 0, nil
+	}
+
+	var total int64
+	query := applyFilters(r.db.Model(&models.Product{}), filter).
+		Where("products.search_vector @@ to_tsquery('simple', ?)", tsquery)
+
+	// Get total count
+	if !filter.SkipTotal {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// Rank and highlight the page of matches
+	var hits []searchHit
+	if err := query.
+		Select(`products.id,
+			ts_rank(products.search_vector, to_tsquery('simple', ?)) AS rank,
+			ts_headline('simple', products.search_document, to_tsquery('simple', ?), 'StartSel=<mark>, StopSel=</mark>') AS highlight`,
+			tsquery, tsquery).
+		Order("rank DESC").
+		Order("products.id").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []SearchResult{}, total, nil
+	}
+
+	// Load the matching products with their relations
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	var products []models.Product
+	if err := withRelations(inScope(r.db.Where("id IN ?", ids), filter.Scope)).
+		Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.attachBreadcrumbs(pointers(products)...); err != nil {
+		return nil, 0, err
+	}
+	if err := attachProductAvailability(r.db, pointers(products)...); err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if product, ok := byID[hit.ID]; ok {
+			results = append(results, SearchResult{Product: product, Rank: hit.Rank, Highlight: hit.Highlight})
+		}
+	}
+
+	return results, total, nil
+}
+
+// prefixQuery turns free text into a tsquery where every word must match as a prefix,
+// e.g. "sku001 variant" becomes "sku001:* & variant:*". Characters other than letters
+// and digits separate words, so the result is always a valid tsquery.
+func prefixQuery(terms string) string {
+	words := strings.FieldsFunc(strings.ToLower(terms), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
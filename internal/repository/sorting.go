@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/common"
+	"gorm.io/gorm"
+)
+
+// orderBy applies the sort fields using their columns, then idColumn as the tie-breaker
+// so that every page is stable.
+func orderBy(query *gorm.DB, sort []common.SortField, columns map[string]string, idColumn string) *gorm.DB {
+	for _, field := range sort {
+		column := columns[field.Name]
+		if field.Desc {
+			column += " DESC"
+		}
+		query = query.Order(column)
+	}
+	return query.Order(idColumn)
+}
+
+// keysetCondition selects the rows that come after the cursor in the given sort order,
+// with idColumn as the final ascending key.
+func keysetCondition(sort []common.SortField, columns map[string]string, idColumn string, cursor common.Cursor) (string, []interface{}) {
+	type key struct {
+		column string
+		desc   bool
+		value  interface{}
+	}
+
+	keys := make([]key, 0, len(sort)+1)
+	for i, field := range sort {
+		keys = append(keys, key{column: columns[field.Name], desc: field.Desc, value: cursor.Values[i]})
+	}
+	keys = append(keys, key{column: idColumn, value: cursor.ID})
+
+	// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND id > z), with < for descending keys
+	var alternatives []string
+	var args []interface{}
+	for i, current := range keys {
+		var conditions []string
+		for _, previous := range keys[:i] {
+			conditions = append(conditions, previous.column+" = ?")
+			args = append(args, previous.value)
+		}
+
+		operator := " > ?"
+		if current.desc {
+			operator = " < ?"
+		}
+		conditions = append(conditions, current.column+operator)
+		args = append(args, current.value)
+
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
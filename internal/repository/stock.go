@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockInterface interface {
+	GetStock(sku string) (*StockLevel, error)
+	AdjustStock(sku, warehouse string, delta int) (*StockLevel, error)
+	SetStock(sku, warehouse string, quantity int) (*StockLevel, error)
+}
+
+// StockLevel is the stock of a variant across its warehouses.
+type StockLevel struct {
+	SKU        string                `json:"sku"`
+	Quantity   int                   `json:"quantity"`
+	Available  int                   `json:"available"`
+	InStock    bool                  `json:"inStock"`
+	Warehouses []models.VariantStock `json:"warehouses"`
+}
+
+type Stock struct {
+	db database.Database
+}
+
+func NewStock(db database.Database) *Stock {
+	return &Stock{
+		db: db,
+	}
+}
+
+// GetStock returns the stock of the variant with the given SKU.
+func (r *Stock) GetStock(sku string) (*StockLevel, error) {
+	var variant models.Variant
+	if err := r.db.Where("sku = ?", sku).Select("id", "sku").First(&variant).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return stockLevel(r.db, variant)
+}
+
+// AdjustStock atomically adds delta to the stock of a variant in a warehouse. A negative delta
+// that would leave less stock than its active reservations hold there fails with
+// ErrInsufficientStock and changes nothing, so confirming them cannot oversell.
+func (r *Stock) AdjustStock(sku, warehouse string, delta int) (*StockLevel, error) {
+	var level *StockLevel
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.Variant
+		if err := tx.Where("sku = ?", sku).Select("id", "sku").First(&variant).Error; err != nil {
+			return translateError(err)
+		}
+
+		if delta >= 0 {
+			if err := tx.Exec(
+				`INSERT INTO variant_stock (variant_id, warehouse, quantity, updated_at) VALUES (?, ?, ?, NOW())
+				ON CONFLICT (variant_id, warehouse) DO UPDATE
+				SET quantity = variant_stock.quantity + EXCLUDED.quantity, updated_at = NOW()`,
+				variant.ID, warehouse, delta,
+			).Error; err != nil {
+				return err
+			}
+		} else {
+			// The stock row is locked while checking, like reservations lock it, so concurrent
+			// decrements and reservations serialize on it and cannot oversell.
+			var stock models.VariantStock
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("variant_id = ? AND warehouse = ?", variant.ID, warehouse).
+				First(&stock).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrInsufficientStock
+				}
+				return err
+			}
+
+			reserved, err := activeReserved(tx, variant.ID, warehouse)
+			if err != nil {
+				return err
+			}
+			if int64(stock.Quantity+delta) < reserved {
+				return ErrInsufficientStock
+			}
+
+			if err := tx.Exec(
+				"UPDATE variant_stock SET quantity = quantity + ?, updated_at = NOW() WHERE variant_id = ? AND warehouse = ?",
+				delta, variant.ID, warehouse,
+			).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		level, err = stockLevel(tx, variant)
+		return err
+	})
+	return level, err
+}
+
+// SetStock overwrites the stock of a variant in a warehouse, e.g. after a stock count. Like a
+// decrement, a quantity below what its active reservations hold there fails with
+// ErrInsufficientStock and changes nothing.
+func (r *Stock) SetStock(sku, warehouse string, quantity int) (*StockLevel, error) {
+	var level *StockLevel
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.Variant
+		if err := tx.Where("sku = ?", sku).Select("id", "sku").First(&variant).Error; err != nil {
+			return translateError(err)
+		}
+
+		// The stock row is locked while checking, as in AdjustStock
+		var stock models.VariantStock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("variant_id = ? AND warehouse = ?", variant.ID, warehouse).
+			First(&stock).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		reserved, err := activeReserved(tx, variant.ID, warehouse)
+		if err != nil {
+			return err
+		}
+		if int64(quantity) < reserved {
+			return ErrInsufficientStock
+		}
+
+		if err := tx.Exec(
+			`INSERT INTO variant_stock (variant_id, warehouse, quantity, updated_at) VALUES (?, ?, ?, NOW())
+			ON CONFLICT (variant_id, warehouse) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = NOW()`,
+			variant.ID, warehouse, quantity,
+		).Error; err != nil {
+			return err
+		}
+
+		level, err = stockLevel(tx, variant)
+		return err
+	})
+	return level, err
+}
+
+// stockLevel loads the warehouse stock and availability of a variant.
+func stockLevel(db database.Database, variant models.Variant) (*StockLevel, error) {
+	level := &StockLevel{SKU: variant.SKU}
+	if err := db.Where("variant_id = ?", variant.ID).Order("warehouse").Find(&level.Warehouses).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Raw(
+		"SELECT quantity, available FROM variant_availability WHERE variant_id = ?", variant.ID,
+	).Row().Scan(&level.Quantity, &level.Available); err != nil {
+		return nil, err
+	}
+
+	level.InStock = level.Available > 0
+	return level, nil
+}
+
+// attachAvailability sets how much of each variant can be sold, loading it in one query.
+func attachAvailability(db database.Database, variants ...*models.Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(variants))
+	for i, variant := range variants {
+		ids[i] = variant.ID
+	}
+
+	var rows []struct {
+		VariantID uint
+		Available int
+	}
+	if err := db.Raw("SELECT variant_id, available FROM variant_availability WHERE variant_id IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	available := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		available[row.VariantID] = row.Available
+	}
+	for _, variant := range variants {
+		variant.ApplyAvailability(available[variant.ID])
+	}
+	return nil
+}
+
+// attachProductAvailability sets the availability of every variant of the products, and
+// whether each product is in stock.
+func attachProductAvailability(db database.Database, products ...*models.Product) error {
+	var variants []*models.Variant
+	for _, product := range products {
+		variants = append(variants, pointers(product.Variants)...)
+	}
+	if err := attachAvailability(db, variants...); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		product.ApplyVariantAvailability()
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/internal/database"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VariantsInterface interface {
+	GetVariantsByProductCode(code string, scope ProductScope) ([]models.Variant, error)
+	GetVariant(productCode, sku string) (*models.Variant, error)
+	GetVariantsBySKU(skus []string, scope ProductScope) ([]models.Variant, error)
+	CreateVariant(productCode string, variant *models.Variant, actor string) error
+	UpdateVariant(variant *models.Variant, actor string) error
+	DeleteVariant(productCode, sku string) error
+	RestoreVariant(productCode, sku string) (*models.Variant, error)
+	SetVariantPrice(productCode, sku, currency string, amount decimal.Decimal, actor string) (*models.VariantPrice, error)
+	DeleteVariantPrice(productCode, sku, currency, actor string) error
+}
+
+type Variants struct {
+	db database.Database
+}
+
+func NewVariants(db database.Database) *Variants {
+	return &Variants{
+		db: db,
+	}
+}
+
+// GetVariantsByProductCode returns the variants of a product, each linked to the product,
+// or ErrNotFound if the product does not exist or is outside the scope.
+func (r *Variants) GetVariantsByProductCode(code string, scope ProductScope) ([]models.Variant, error) {
+	product, err := r.product(code, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []models.Variant
+	if err := r.db.Where("product_id = ?", product.ID).
+		Preload("Options", withOptionNames).
+		Preload("Prices").
+		Preload("Sales", activeSales).
+		Order("id").
+		Find(&variants).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range variants {
+		variants[i].Product = product
+	}
+	if err := attachAvailability(r.db, pointers(variants)...); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *Variants) GetVariant(productCode, sku string) (*models.Variant, error) {
+	var variant models.Variant
+	if err := r.db.Model(&models.Variant{}).
+		Joins("JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL").
+		Where("products.code = ? AND product_variants.sku = ?", productCode, sku).
+		Preload("Product.Prices").
+		Preload("Product.Sales", activeSales).
+		Preload("Options", withOptionNames).
+		Preload("Prices").
+		Preload("Sales", activeSales).
+		First(&variant).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if err := attachAvailability(r.db, &variant); err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetVariantsBySKU returns the variants with the given SKUs, each with its product and category.
+// SKUs that do not exist, or whose product is outside the scope, are left out of the result.
+func (r *Variants) GetVariantsBySKU(skus []string, scope ProductScope) ([]models.Variant, error) {
+	query := r.db.Model(&models.Variant{}).
+		Joins("JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL")
+
+	var variants []models.Variant
+	if err := inScope(query, scope).
+		Where("product_variants.sku IN ?", skus).
+		Preload("Product.Category").
+		Preload("Product.Prices").
+		Preload("Product.Sales", activeSales).
+		Preload("Options", withOptionNames).
+		Preload("Prices").
+		Preload("Sales", activeSales).
+		Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	if err := attachAvailability(r.db, pointers(variants)...); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// CreateVariant adds a variant to a product. The variant must set one value for each option
+// of the product, in a combination no other variant of the product has.
+func (r *Variants) CreateVariant(productCode string, variant *models.Variant, actor string) error {
+	product, err := r.product(productCode, AllProducts)
+	if err != nil {
+		return err
+	}
+
+	variant.ProductID = product.ID
+	variant.Product = product
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := resolveOptions(tx, variant); err != nil {
+			return err
+		}
+		if err := tx.Model(variant).Omit(clause.Associations).Create(variant).Error; err != nil {
+			return translateError(err)
+		}
+		if err := recordVariantPrice(tx, variant, decimal.NullDecimal{}, actor); err != nil {
+			return err
+		}
+		return replaceOptionValues(tx, variant)
+	})
+}
+
+// UpdateVariant persists all fields of a variant, replacing its option values. A price change
+// is recorded in the price history, attributed to actor.
+func (r *Variants) UpdateVariant(variant *models.Variant, actor string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Variant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "price").
+			First(&current, variant.ID).Error; err != nil {
+			return translateError(err)
+		}
+
+		if err := resolveOptions(tx, variant); err != nil {
+			return err
+		}
+		if err := tx.Model(variant).Omit(clause.Associations).Save(variant).Error; err != nil {
+			return translateError(err)
+		}
+		if err := recordVariantPrice(tx, variant, current.Price, actor); err != nil {
+			return err
+		}
+		if err := replaceOptionValues(tx, variant); err != nil {
+			return err
+		}
+		return attachAvailability(tx, variant)
+	})
+}
+
+// DeleteVariant soft deletes a variant; its SKU can then be reused.
+func (r *Variants) DeleteVariant(productCode, sku string) error {
+	variant, err := r.GetVariant(productCode, sku)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.Delete(variant).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// RestoreVariant restores the most recently deleted variant of the product with the SKU. It fails
+// with ErrConflict if the SKU or the option combination has been reused meanwhile.
+func (r *Variants) RestoreVariant(productCode, sku string) (*models.Variant, error) {
+	product, err := r.product(productCode, AllProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.Variant
+		if err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND sku = ? AND deleted_at IS NOT NULL", product.ID, sku).
+			Select("id").
+			Order("deleted_at DESC").
+			First(&variant).Error; err != nil {
+			return translateError(err)
+		}
+
+		if err := tx.Unscoped().Model(&variant).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return translateError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetVariant(productCode, sku)
+}
+
+// resolveOptions matches the variant's option values to the options of its product by name,
+// orders them like the product options and checks no other variant has the same combination.
+// A variant without a name is named after its option values, e.g. "M / Black".
+func resolveOptions(tx *gorm.DB, variant *models.Variant) error {
+	var options []models.ProductOption
+	if err := tx.Where("product_id = ?", variant.ProductID).Order("position").Find(&options).Error; err != nil {
+		return err
+	}
+	if len(variant.Options) != len(options) {
+		return ErrInvalidOptions
+	}
+
+	positions := make(map[string]int, len(options))
+	for i, option := range options {
+		positions[strings.ToLower(option.Name)] = i
+	}
+
+	values := make([]models.VariantOption, len(options))
+	for _, value := range variant.Options {
+		i, ok := positions[strings.ToLower(value.Name)]
+		if !ok || values[i].OptionID != 0 || value.Value == "" {
+			return ErrInvalidOptions
+		}
+		values[i] = models.VariantOption{OptionID: options[i].ID, Name: options[i].Name, Value: value.Value}
+	}
+	variant.Options = values
+	if variant.Name == "" {
+		names := make([]string, len(values))
+		for i, value := range values {
+			names[i] = value.Value
+		}
+		variant.Name = strings.Join(names, " / ")
+	}
+
+	variant.OptionSignature = optionSignature(values)
+	if variant.OptionSignature == nil {
+		return nil
+	}
+
+	var duplicates int64
+	if err := tx.Model(&models.Variant{}).
+		Where("product_id = ? AND option_signature = ? AND id <> ?", variant.ProductID, *variant.OptionSignature, variant.ID).
+		Count(&duplicates).Error; err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return ErrDuplicateOptions
+	}
+	return nil
+}
+
+// optionSignature joins the lower-cased option values, e.g. "size=m|color=black".
+func optionSignature(values []models.VariantOption) *string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strings.ToLower(value.Name) + "=" + strings.ToLower(value.Value)
+	}
+	signature := strings.Join(parts, "|")
+	return &signature
+}
+
+// replaceOptionValues stores the variant's resolved option values, replacing any previous ones.
+func replaceOptionValues(tx *gorm.DB, variant *models.Variant) error {
+	if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.VariantOption{}).Error; err != nil {
+		return err
+	}
+	if len(variant.Options) == 0 {
+		return nil
+	}
+
+	for i := range variant.Options {
+		variant.Options[i].VariantID = variant.ID
+	}
+	return tx.Create(&variant.Options).Error
+}
+
+// withOptionNames loads variant option values with the name of their option, in option order.
+func withOptionNames(db *gorm.DB) *gorm.DB {
+	return db.Select("variant_option_values.*, product_options.name").
+		Joins("JOIN product_options ON product_options.id = variant_option_values.option_id").
+		Order("product_options.position")
+}
+
+// product looks up a live product in the scope.
+func (r *Variants) product(code string, scope ProductScope) (*models.Product, error) {
+	var product models.Product
+	if err := inScope(r.db.Where("code = ?", code), scope).
+		Preload("Prices").
+		Preload("Sales", activeSales).
+		First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &product, nil
+}
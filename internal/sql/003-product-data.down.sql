@@ -0,0 +1 @@
+-- Nothing to revert; sample products are removed with make seed ARGS=--reset.
@@ -0,0 +1 @@
+-- Nothing to revert; sample categories are removed with make seed ARGS=--reset.
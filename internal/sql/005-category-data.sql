@@ -1,6 +1,2 @@
--- Insert categories
-INSERT INTO categories (code, name) VALUES
-('CLOTHING', 'Clothing'),
-('SHOES', 'Shoes'),
-('ACCESSORIES', 'Accessories');
-
+-- Sample categories are loaded from internal/fixtures (make seed). The version stays so the
+-- migrations after it keep their numbers.
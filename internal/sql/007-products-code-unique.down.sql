@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS products_code_key;
+ALTER TABLE products ALTER COLUMN code DROP NOT NULL;
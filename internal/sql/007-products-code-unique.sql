@@ -0,0 +1,3 @@
+-- Product codes identify products in the API, so they must be present and unique
+ALTER TABLE products ALTER COLUMN code SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS products_code_key ON products (code);
@@ -0,0 +1 @@
+-- Nothing to revert: NULL stays the way to inherit the product price
@@ -0,0 +1,2 @@
+-- Variants without their own price are stored as NULL, so 0.00 can be a real price
+UPDATE product_variants SET price = NULL WHERE price = 0;
@@ -0,0 +1,3 @@
+-- Categories can be nested, e.g. Clothing > Dresses > Midi Dresses
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id);
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
@@ -0,0 +1,13 @@
+-- Products can belong to several categories; products.category_id stays the primary category
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (product_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories (category_id);
+
+-- Every primary category is also an assignment
+INSERT INTO product_categories (product_id, category_id)
+SELECT id, category_id FROM products WHERE category_id IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS products_price_id_idx;
+DROP INDEX IF EXISTS products_created_at_id_idx;
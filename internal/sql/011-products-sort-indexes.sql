@@ -0,0 +1,3 @@
+-- Indexes backing keyset pagination on the sortable product columns, with id as tie-breaker
+CREATE INDEX IF NOT EXISTS products_price_id_idx ON products (price, id);
+CREATE INDEX IF NOT EXISTS products_created_at_id_idx ON products (created_at, id);
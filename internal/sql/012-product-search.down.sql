@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS product_variants_search_touch ON product_variants;
+DROP FUNCTION IF EXISTS product_variants_search_touch();
+DROP TRIGGER IF EXISTS products_search_update ON products;
+DROP FUNCTION IF EXISTS products_search_update();
+
+DROP INDEX IF EXISTS products_search_vector_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE products DROP COLUMN IF EXISTS search_document;
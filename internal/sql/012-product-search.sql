@@ -0,0 +1,48 @@
+-- Full-text search over product codes and variant names and SKUs.
+-- search_document keeps the indexed text so results can be highlighted.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_document TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    variant_text TEXT;
+BEGIN
+    SELECT coalesce(string_agg(v.name || ' ' || coalesce(v.sku, ''), ' ' ORDER BY v.id), '')
+    INTO variant_text
+    FROM product_variants v
+    WHERE v.product_id = NEW.id;
+
+    NEW.search_document := concat_ws(' ', NEW.code, variant_text);
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'A') ||
+        setweight(to_tsvector('simple', variant_text), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_search_update ON products;
+CREATE TRIGGER products_search_update
+    BEFORE INSERT OR UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_update();
+
+-- Variant changes touch their product so its search vector is rebuilt
+CREATE OR REPLACE FUNCTION product_variants_search_touch() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE products SET code = code WHERE id = OLD.product_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE products SET code = code WHERE id = NEW.product_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_variants_search_touch ON product_variants;
+CREATE TRIGGER product_variants_search_touch
+    AFTER INSERT OR UPDATE OR DELETE ON product_variants
+    FOR EACH ROW EXECUTE FUNCTION product_variants_search_touch();
+
+-- Build the search vector of existing products
+UPDATE products SET code = code;
@@ -0,0 +1,25 @@
+-- Search codes, variant names and SKUs only, as before
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    variant_text TEXT;
+BEGIN
+    SELECT coalesce(string_agg(v.name || ' ' || coalesce(v.sku, ''), ' ' ORDER BY v.id), '')
+    INTO variant_text
+    FROM product_variants v
+    WHERE v.product_id = NEW.id;
+
+    NEW.search_document := concat_ws(' ', NEW.code, variant_text);
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'A') ||
+        setweight(to_tsvector('simple', variant_text), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS product_images;
+ALTER TABLE products DROP COLUMN IF EXISTS name;
+ALTER TABLE products DROP COLUMN IF EXISTS description;
+ALTER TABLE products DROP COLUMN IF EXISTS brand;
+
+-- Rebuild the search vectors without the dropped attributes
+UPDATE products SET code = code;
@@ -0,0 +1,40 @@
+-- Descriptive attributes shown in the storefront
+ALTER TABLE products ADD COLUMN IF NOT EXISTS name VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS brand VARCHAR(128) NOT NULL DEFAULT '';
+
+-- Ordered product images
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    url VARCHAR(1024) NOT NULL,
+    alt_text VARCHAR(256) NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (product_id, position)
+);
+
+-- Search the name, brand and description too
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    variant_text TEXT;
+BEGIN
+    SELECT coalesce(string_agg(v.name || ' ' || coalesce(v.sku, ''), ' ' ORDER BY v.id), '')
+    INTO variant_text
+    FROM product_variants v
+    WHERE v.product_id = NEW.id;
+
+    NEW.search_document := concat_ws(' ', NEW.code, NEW.name, NEW.brand, variant_text, NEW.description);
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'A') ||
+        setweight(to_tsvector('simple', NEW.name), 'A') ||
+        setweight(to_tsvector('simple', NEW.brand), 'B') ||
+        setweight(to_tsvector('simple', variant_text), 'C') ||
+        setweight(to_tsvector('simple', NEW.description), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Rebuild the search vectors of existing products with the attributes
+UPDATE products SET code = code;
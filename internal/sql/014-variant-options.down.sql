@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS product_variants_option_signature_key;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS option_signature;
+DROP TABLE IF EXISTS variant_option_values;
+DROP TABLE IF EXISTS product_options;
@@ -0,0 +1,24 @@
+-- Option dimensions per product, e.g. Size and Color
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (product_id, name)
+);
+
+-- The value each variant takes for each option of its product
+CREATE TABLE IF NOT EXISTS variant_option_values (
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    option_id INTEGER NOT NULL REFERENCES product_options(id) ON DELETE CASCADE,
+    value VARCHAR(64) NOT NULL,
+    PRIMARY KEY (variant_id, option_id)
+);
+CREATE INDEX IF NOT EXISTS variant_option_values_option_id_value_idx ON variant_option_values (option_id, lower(value));
+
+-- No two variants of a product share an option combination
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS option_signature VARCHAR(512) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_option_signature_key
+    ON product_variants (product_id, option_signature) WHERE option_signature IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS variant_availability;
+DROP TABLE IF EXISTS variant_stock;
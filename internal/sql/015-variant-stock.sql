@@ -0,0 +1,17 @@
+-- Stock on hand per variant and warehouse; the check keeps concurrent decrements from going negative
+CREATE TABLE IF NOT EXISTS variant_stock (
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    warehouse VARCHAR(32) NOT NULL DEFAULT 'default',
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (variant_id, warehouse)
+);
+
+-- Quantity that can be sold per variant, across warehouses
+CREATE OR REPLACE VIEW variant_availability AS
+SELECT v.id AS variant_id,
+       COALESCE(SUM(s.quantity), 0)::INTEGER AS quantity,
+       COALESCE(SUM(s.quantity), 0)::INTEGER AS available
+FROM product_variants v
+LEFT JOIN variant_stock s ON s.variant_id = v.id
+GROUP BY v.id;
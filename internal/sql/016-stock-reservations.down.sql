@@ -0,0 +1,10 @@
+-- Available to sell is the stock on hand again
+CREATE OR REPLACE VIEW variant_availability AS
+SELECT v.id AS variant_id,
+       COALESCE(SUM(s.quantity), 0)::INTEGER AS quantity,
+       COALESCE(SUM(s.quantity), 0)::INTEGER AS available
+FROM product_variants v
+LEFT JOIN variant_stock s ON s.variant_id = v.id
+GROUP BY v.id;
+
+DROP TABLE IF EXISTS stock_reservations;
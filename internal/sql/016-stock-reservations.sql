@@ -0,0 +1,31 @@
+-- Quantities held for checkouts; active reservations expire unless confirmed or released
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    warehouse VARCHAR(32) NOT NULL DEFAULT 'default',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'confirmed', 'released', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS stock_reservations_active_idx
+    ON stock_reservations (variant_id, warehouse) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS stock_reservations_expires_at_idx
+    ON stock_reservations (expires_at) WHERE status = 'active';
+
+-- Available to sell is the stock minus the reservations that are active and not yet expired
+CREATE OR REPLACE VIEW variant_availability AS
+SELECT v.id AS variant_id,
+       COALESCE(s.quantity, 0)::INTEGER AS quantity,
+       GREATEST(COALESCE(s.quantity, 0) - COALESCE(r.quantity, 0), 0)::INTEGER AS available
+FROM product_variants v
+LEFT JOIN (
+    SELECT variant_id, SUM(quantity) AS quantity FROM variant_stock GROUP BY variant_id
+) s ON s.variant_id = v.id
+LEFT JOIN (
+    SELECT variant_id, SUM(quantity) AS quantity FROM stock_reservations
+    WHERE status = 'active' AND expires_at > NOW()
+    GROUP BY variant_id
+) r ON r.variant_id = v.id;
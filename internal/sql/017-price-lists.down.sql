@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS variant_prices;
+DROP TABLE IF EXISTS product_prices;
@@ -0,0 +1,20 @@
+-- Prices in currencies other than the base currency (EUR), which stays in products.price and
+-- product_variants.price. A variant without a base price inherits the product price in every currency.
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL CHECK (currency IN ('GBP', 'USD', 'CHF')),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (product_id, currency)
+);
+CREATE INDEX IF NOT EXISTS product_prices_currency_amount_idx ON product_prices (currency, amount);
+
+CREATE TABLE IF NOT EXISTS variant_prices (
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL CHECK (currency IN ('GBP', 'USD', 'CHF')),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (variant_id, currency)
+);
@@ -0,0 +1,17 @@
+-- Scheduled sale prices. A sale belongs to either a product or a variant and replaces its
+-- price in one currency from starts_at until, but not including, ends_at. Both are instants
+-- compared against NOW(), so they keep their time zone.
+CREATE TABLE IF NOT EXISTS sale_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL DEFAULT 'EUR' CHECK (currency IN ('EUR', 'GBP', 'USD', 'CHF')),
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK ((product_id IS NULL) <> (variant_id IS NULL)),
+    CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS sale_prices_product_idx ON sale_prices (product_id, currency, starts_at) WHERE product_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS sale_prices_variant_idx ON sale_prices (variant_id, currency, starts_at) WHERE variant_id IS NOT NULL;
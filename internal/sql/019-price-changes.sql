@@ -0,0 +1,24 @@
+-- Audit trail of list price changes, written in the same transaction as the change. The SKU is
+-- copied so the history of a variant stays readable after the variant is deleted.
+CREATE TABLE IF NOT EXISTS price_changes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    sku VARCHAR(32) NOT NULL DEFAULT '',
+    currency CHAR(3) NOT NULL DEFAULT 'EUR',
+    old_price DECIMAL(10, 2),
+    new_price DECIMAL(10, 2),
+    actor VARCHAR(128) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS price_changes_product_changed_at_idx ON price_changes (product_id, changed_at DESC, id DESC);
+
+-- The seeded prices are the first entries of the history
+INSERT INTO price_changes (product_id, currency, new_price, actor, changed_at)
+SELECT p.id, 'EUR', p.price, 'seed', COALESCE(p.created_at, NOW())
+FROM products p;
+
+INSERT INTO price_changes (product_id, variant_id, sku, currency, new_price, actor, changed_at)
+SELECT v.product_id, v.id, v.sku, 'EUR', v.price, 'seed', COALESCE(v.created_at, NOW())
+FROM product_variants v
+WHERE v.price IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS products_status_idx;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_publication_window_check;
+ALTER TABLE products DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE products DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE products DROP COLUMN IF EXISTS status;
@@ -0,0 +1,13 @@
+-- Publication workflow. Only published products are public, and only between publish_at and
+-- unpublish_at when those are set. Products that existed before stay visible: the column is
+-- added as published for them, new products then start as drafts.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published', 'archived'));
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_publication_window_check;
+ALTER TABLE products ADD CONSTRAINT products_publication_window_check
+    CHECK (publish_at IS NULL OR unpublish_at IS NULL OR unpublish_at > publish_at);
+
+CREATE INDEX IF NOT EXISTS products_status_idx ON products (status, publish_at, unpublish_at);
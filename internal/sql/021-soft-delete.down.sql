@@ -0,0 +1,53 @@
+-- Without deleted_at, deleted rows would come back as live ones or clash with the codes and SKUs
+-- that reused theirs. Rather than dropping them, refuse to revert while any are left; restore or
+-- remove them by hand first.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM products WHERE deleted_at IS NOT NULL)
+        OR EXISTS (SELECT 1 FROM product_variants WHERE deleted_at IS NOT NULL)
+        OR EXISTS (SELECT 1 FROM categories WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'soft deleted products, variants or categories exist; restore or remove them before reverting';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS products_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_code_key ON products (code);
+DROP INDEX IF EXISTS products_deleted_at_idx;
+
+DROP INDEX IF EXISTS product_variants_sku_key;
+ALTER TABLE product_variants ADD CONSTRAINT product_variants_sku_key UNIQUE (sku);
+DROP INDEX IF EXISTS product_variants_deleted_at_idx;
+
+DROP INDEX IF EXISTS product_variants_option_signature_key;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_option_signature_key
+    ON product_variants (product_id, option_signature) WHERE option_signature IS NOT NULL;
+
+DROP INDEX IF EXISTS categories_code_key;
+ALTER TABLE categories ADD CONSTRAINT categories_code_key UNIQUE (code);
+DROP INDEX IF EXISTS categories_deleted_at_idx;
+
+-- Search all variants again
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    variant_text TEXT;
+BEGIN
+    SELECT coalesce(string_agg(v.name || ' ' || coalesce(v.sku, ''), ' ' ORDER BY v.id), '')
+    INTO variant_text
+    FROM product_variants v
+    WHERE v.product_id = NEW.id;
+
+    NEW.search_document := concat_ws(' ', NEW.code, NEW.name, NEW.brand, variant_text, NEW.description);
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'A') ||
+        setweight(to_tsvector('simple', NEW.name), 'A') ||
+        setweight(to_tsvector('simple', NEW.brand), 'B') ||
+        setweight(to_tsvector('simple', variant_text), 'C') ||
+        setweight(to_tsvector('simple', NEW.description), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
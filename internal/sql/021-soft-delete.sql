@@ -0,0 +1,43 @@
+-- Soft deletes: deleted products, variants and categories keep their rows, so order history that
+-- references codes and SKUs stays resolvable. Codes and SKUs only need to be unique among rows
+-- that are not deleted, so they can be reused after a delete.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+DROP INDEX IF EXISTS products_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_code_key ON products (code) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS products_deleted_at_idx ON products (deleted_at);
+
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_sku_key ON product_variants (sku) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS product_variants_deleted_at_idx ON product_variants (deleted_at);
+
+DROP INDEX IF EXISTS product_variants_option_signature_key;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_option_signature_key
+    ON product_variants (product_id, option_signature) WHERE option_signature IS NOT NULL AND deleted_at IS NULL;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS categories_code_key ON categories (code) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS categories_deleted_at_idx ON categories (deleted_at);
+
+-- Deleted variants are no longer found by search
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    variant_text TEXT;
+BEGIN
+    SELECT coalesce(string_agg(v.name || ' ' || coalesce(v.sku, ''), ' ' ORDER BY v.id), '')
+    INTO variant_text
+    FROM product_variants v
+    WHERE v.product_id = NEW.id AND v.deleted_at IS NULL;
+
+    NEW.search_document := concat_ws(' ', NEW.code, NEW.name, NEW.brand, variant_text, NEW.description);
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'A') ||
+        setweight(to_tsvector('simple', NEW.name), 'A') ||
+        setweight(to_tsvector('simple', NEW.brand), 'B') ||
+        setweight(to_tsvector('simple', variant_text), 'C') ||
+        setweight(to_tsvector('simple', NEW.description), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
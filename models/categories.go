@@ -1,15 +1,79 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// Category groups products. Categories can be nested through ParentID;
+// a category without a parent is a root category.
 type Category struct {
-	ID        uint      `gorm:"primaryKey"`
-	Code      string    `gorm:"uniqueIndex;not null"`
-	Name      string    `gorm:"not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID        uint           `gorm:"primaryKey"`
+	Code      string         `gorm:"uniqueIndex;not null"`
+	Name      string         `gorm:"not null"`
+	ParentID  *uint          `gorm:"index"`
+	Parent    *Category      `gorm:"foreignKey:ParentID" json:",omitempty"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// Children is only populated when categories are returned as a tree.
+	Children []Category `gorm:"-" json:",omitempty"`
 }
 
 func (c *Category) TableName() string {
 	return "categories"
 }
+
+// Breadcrumb is one step of the path from a root category down to a product's category.
+type Breadcrumb struct {
+	Code string
+	Name string
+}
+
+// BuildCategoryTree nests categories under their parents and returns the roots.
+// Categories whose parent is not in the list are treated as roots.
+func BuildCategoryTree(categories []Category) []Category {
+	children := make(map[uint][]Category)
+	known := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID != nil && known[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+			continue
+		}
+		roots = append(roots, category)
+	}
+
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots)
+}
+
+// CategoryPath returns the breadcrumbs from the root down to the given category,
+// looking ancestors up in byID. It stops at ancestors that are missing from byID.
+func CategoryPath(categoryID uint, byID map[uint]Category) []Breadcrumb {
+	var path []Breadcrumb
+	seen := make(map[uint]bool)
+
+	for id := &categoryID; id != nil && !seen[*id]; {
+		category, ok := byID[*id]
+		if !ok {
+			break
+		}
+		seen[*id] = true
+		path = append([]Breadcrumb{{Code: category.Code, Name: category.Name}}, path...)
+		id = category.ParentID
+	}
+	return path
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ProductImage is an image of a product. Images are shown in the order of their Position.
+type ProductImage struct {
+	ID        uint      `gorm:"primaryKey"`
+	ProductID uint      `gorm:"not null"`
+	URL       string    `gorm:"not null"`
+	AltText   string    `gorm:"not null"`
+	Position  int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (i *ProductImage) TableName() string {
+	return "product_images"
+}
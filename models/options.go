@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ProductOption is an option dimension of a product, e.g. Size or Color.
+// Every variant of the product takes exactly one value per option.
+type ProductOption struct {
+	ID        uint      `gorm:"primaryKey"`
+	ProductID uint      `gorm:"not null"`
+	Name      string    `gorm:"not null"`
+	Position  int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (o *ProductOption) TableName() string {
+	return "product_options"
+}
+
+// VariantOption is the value a variant takes for one of its product's options, e.g. Size M.
+// Name is read from the product option.
+type VariantOption struct {
+	VariantID uint   `gorm:"primaryKey" json:"-"`
+	OptionID  uint   `gorm:"primaryKey" json:"-"`
+	Name      string `gorm:"->"`
+	Value     string `gorm:"not null"`
+}
+
+func (o *VariantOption) TableName() string {
+	return "variant_option_values"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceChange records a change of the list price of a product or, when SKU is set, of one of its
+// variants, in Currency. A NULL OldPrice means the price was first set; a NULL NewPrice means a
+// currency was removed from a price list, or in EUR that the variant inherits the product price.
+type PriceChange struct {
+	ID        uint                `gorm:"primaryKey"`
+	ProductID uint                `gorm:"not null" json:"-"`
+	VariantID *uint               `json:"-"`
+	SKU       string              `json:",omitempty"`
+	Currency  string              `gorm:"not null"`
+	OldPrice  decimal.NullDecimal `gorm:"type:decimal(10,2);null"`
+	NewPrice  decimal.NullDecimal `gorm:"type:decimal(10,2);null"`
+	Actor     string              `gorm:"not null"`
+	ChangedAt time.Time           `gorm:"not null;default:now()"`
+}
+
+func (c *PriceChange) TableName() string {
+	return "price_changes"
+}
@@ -0,0 +1,253 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ApplyProductPrice sets the effective price of the variant: its own price when it has one,
+// otherwise the price of its product, in which case PriceInherited is true and the variant
+// shares the product's sale. An active sale of the variant itself takes precedence over both.
+func (v *Variant) ApplyProductPrice(product *Product) {
+	if v.Currency == "" {
+		v.Currency = BaseCurrency
+	}
+	if v.salePrice != nil {
+		v.EffectivePrice = *v.salePrice
+		v.PriceInherited = !v.Price.Valid
+		return
+	}
+	if v.Price.Valid {
+		v.EffectivePrice = v.Price.Decimal
+		v.PriceInherited = false
+		return
+	}
+
+	v.EffectivePrice = product.Price
+	v.PriceInherited = true
+	v.OriginalPrice = product.OriginalPrice
+	v.DiscountPercent = product.DiscountPercent
+}
+
+// ApplyVariantPrices sets the effective price of every variant of the product.
+func (p *Product) ApplyVariantPrices() {
+	if p.Currency == "" {
+		p.Currency = BaseCurrency
+	}
+	for i := range p.Variants {
+		p.Variants[i].ApplyProductPrice(p)
+	}
+}
+
+// ApplyProductPrices resolves the prices of a variant loaded together with its product, in the
+// base currency, applying the active sales of both.
+func (v *Variant) ApplyProductPrices() {
+	// Every product and variant has a price in the base currency
+	_ = v.ApplyProductCurrency(BaseCurrency)
+}
+
+// ApplyProductCurrency resolves the prices of a variant loaded together with its product in the
+// currency, using the loaded price lists and the active sales in that currency. Like
+// Product.ApplyCurrency, a missing price is an ErrNoPrice error rather than a fallback.
+func (v *Variant) ApplyProductCurrency(currency string) error {
+	if v.Product == nil {
+		return nil
+	}
+	list := v.Product.baseListPrice()
+	if currency != BaseCurrency {
+		price, ok := findPrice(v.Product.Prices, currency)
+		if !ok {
+			return fmt.Errorf("%w: product %s has no %s price", ErrNoPrice, v.Product.Code, currency)
+		}
+		list = price
+	}
+	v.Product.Price = list
+	v.Product.Currency = currency
+	v.Product.applySale(list)
+
+	if err := v.ApplyCurrency(currency); err != nil {
+		return err
+	}
+	v.applySale(list)
+	v.ApplyProductPrice(v.Product)
+	return nil
+}
+
+// BaseCurrency is the currency of Product.Price and Variant.Price as stored on the rows.
+// Prices in other currencies come from the price lists.
+const BaseCurrency = "EUR"
+
+// Currencies lists the currencies products are sold in.
+var Currencies = []string{BaseCurrency, "GBP", "USD", "CHF"}
+
+// ErrNoPrice is returned when a product or variant has no price in the requested currency.
+var ErrNoPrice = errors.New("no price in the requested currency")
+
+// ProductPrice is the price of a product in a currency other than the base currency.
+type ProductPrice struct {
+	ProductID uint            `gorm:"primaryKey" json:"-"`
+	Currency  string          `gorm:"primaryKey"`
+	Amount    decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+}
+
+func (p *ProductPrice) TableName() string {
+	return "product_prices"
+}
+
+// VariantPrice is the price of a variant in a currency other than the base currency.
+type VariantPrice struct {
+	VariantID uint            `gorm:"primaryKey" json:"-"`
+	Currency  string          `gorm:"primaryKey"`
+	Amount    decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+}
+
+func (p *VariantPrice) TableName() string {
+	return "variant_prices"
+}
+
+// ApplyCurrency switches the prices of the product and its variants to the currency, using
+// the loaded price lists, and applies the active sales in that currency. A variant with its
+// own base price needs its own price in the currency; a variant without one inherits the
+// product price as usual. Nothing falls back to the base currency: a missing price is an
+// ErrNoPrice error. Prices are resolved from the stored base prices, so applying a currency
+// again, or another one, starts over rather than converting or discounting twice.
+func (p *Product) ApplyCurrency(currency string) error {
+	list := p.baseListPrice()
+	if currency != BaseCurrency {
+		price, ok := findPrice(p.Prices, currency)
+		if !ok {
+			return fmt.Errorf("%w: product %s has no %s price", ErrNoPrice, p.Code, currency)
+		}
+		list = price
+	}
+	p.Price = list
+	p.Currency = currency
+
+	for i := range p.Variants {
+		if err := p.Variants[i].ApplyCurrency(currency); err != nil {
+			return err
+		}
+		p.Variants[i].applySale(list)
+	}
+	p.applySale(list)
+	return nil
+}
+
+// ApplyCurrency switches the own price of the variant, if it has one, to the currency.
+func (v *Variant) ApplyCurrency(currency string) error {
+	v.Currency = currency
+	v.Price = v.baseListPrice()
+	if currency == BaseCurrency || !v.Price.Valid {
+		return nil
+	}
+
+	for _, price := range v.Prices {
+		if price.Currency == currency {
+			v.Price = decimal.NewNullDecimal(price.Amount)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: variant %s has no %s price", ErrNoPrice, v.SKU, currency)
+}
+
+func findPrice(prices []ProductPrice, currency string) (decimal.Decimal, bool) {
+	for _, price := range prices {
+		if price.Currency == currency {
+			return price.Amount, true
+		}
+	}
+	return decimal.Decimal{}, false
+}
+
+// SalePrice is a scheduled price of a product, or of one of its variants, in a currency.
+// It replaces the regular price from StartsAt until, but not including, EndsAt.
+type SalePrice struct {
+	ID        uint            `gorm:"primaryKey"`
+	ProductID *uint           `json:"-"`
+	VariantID *uint           `json:"-"`
+	SKU       string          `gorm:"->" json:",omitempty"`
+	Currency  string          `gorm:"not null"`
+	Price     decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+	StartsAt  time.Time       `gorm:"not null"`
+	EndsAt    time.Time       `gorm:"not null"`
+	CreatedAt time.Time       `gorm:"autoCreateTime"`
+}
+
+func (s *SalePrice) TableName() string {
+	return "sale_prices"
+}
+
+// applySale replaces the price of the product with its active sale price in the product
+// currency, keeping list as the original price.
+func (p *Product) applySale(list decimal.Decimal) {
+	p.OriginalPrice, p.DiscountPercent = nil, 0
+	if sale, ok := findSale(p.Sales, p.currency()); ok {
+		p.Price = sale
+		p.OriginalPrice, p.DiscountPercent = &list, discountPercent(list, sale)
+	}
+}
+
+// applySale records the active sale price of the variant in the variant currency, leaving its
+// own Price as is. The original price is the variant's own price, or productList when it
+// inherits.
+func (v *Variant) applySale(productList decimal.Decimal) {
+	v.salePrice, v.OriginalPrice, v.DiscountPercent = nil, nil, 0
+	sale, ok := findSale(v.Sales, v.Currency)
+	if !ok {
+		return
+	}
+	list := productList
+	if v.Price.Valid {
+		list = v.Price.Decimal
+	}
+	v.salePrice = &sale
+	v.OriginalPrice, v.DiscountPercent = &list, discountPercent(list, sale)
+}
+
+// baseListPrice is the price of the product as stored, in the base currency and without its
+// sale. It is taken from Price the first time prices are resolved.
+func (p *Product) baseListPrice() decimal.Decimal {
+	if p.basePrice == nil {
+		price := p.Price
+		p.basePrice = &price
+	}
+	return *p.basePrice
+}
+
+// baseListPrice is the own price of the variant as stored, in the base currency and without its
+// sale. It is taken from Price the first time prices are resolved.
+func (v *Variant) baseListPrice() decimal.NullDecimal {
+	if v.basePrice == nil {
+		price := v.Price
+		v.basePrice = &price
+	}
+	return *v.basePrice
+}
+
+func (p *Product) currency() string {
+	if p.Currency == "" {
+		return BaseCurrency
+	}
+	return p.Currency
+}
+
+// findSale returns the sale price in the currency; sales are loaded active only, latest start first.
+func findSale(sales []SalePrice, currency string) (decimal.Decimal, bool) {
+	for _, sale := range sales {
+		if sale.Currency == currency {
+			return sale.Price, true
+		}
+	}
+	return decimal.Decimal{}, false
+}
+
+// discountPercent is the markdown from list to sale in whole percent.
+func discountPercent(list, sale decimal.Decimal) int {
+	if !list.IsPositive() {
+		return 0
+	}
+	return int(list.Sub(sale).Div(list).Mul(decimal.NewFromInt(100)).Round(0).IntPart())
+}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func nullDec(value string) decimal.NullDecimal {
+	return decimal.NewNullDecimal(dec(value))
+}
+
+// pricedProduct is a product with a GBP price list, an inheriting variant and a variant with
+// its own price in both currencies.
+func pricedProduct() *Product {
+	return &Product{
+		Code:   "PROD001",
+		Price:  dec("10.00"),
+		Prices: []ProductPrice{{Currency: "GBP", Amount: dec("8.00")}},
+		Variants: []Variant{
+			{SKU: "SKU001A"},
+			{SKU: "SKU001B", Price: nullDec("12.00"), Prices: []VariantPrice{{Currency: "GBP", Amount: dec("9.50")}}},
+		},
+	}
+}
+
+func TestApplyProductPrice(t *testing.T) {
+	original := dec("20.00")
+	product := &Product{Price: dec("15.00"), OriginalPrice: &original, DiscountPercent: 25}
+
+	tests := []struct {
+		name      string
+		variant   Variant
+		effective string
+		inherited bool
+		original  *decimal.Decimal
+		discount  int
+	}{
+		{"own price", Variant{Price: nullDec("12.00")}, "12.00", false, nil, 0},
+		{"inherits the product price and sale", Variant{}, "15.00", true, &original, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := tt.variant
+
+			variant.ApplyProductPrice(product)
+
+			assert.True(t, variant.EffectivePrice.Equal(dec(tt.effective)))
+			assert.Equal(t, tt.inherited, variant.PriceInherited)
+			assert.Equal(t, tt.original, variant.OriginalPrice)
+			assert.Equal(t, tt.discount, variant.DiscountPercent)
+			assert.Equal(t, BaseCurrency, variant.Currency, "an unset currency is the base currency")
+		})
+	}
+}
+
+func TestApplyVariantPrices(t *testing.T) {
+	product := pricedProduct()
+
+	product.ApplyVariantPrices()
+
+	assert.Equal(t, BaseCurrency, product.Currency)
+	assert.True(t, product.Variants[0].EffectivePrice.Equal(dec("10.00")))
+	assert.True(t, product.Variants[0].PriceInherited)
+	assert.True(t, product.Variants[1].EffectivePrice.Equal(dec("12.00")))
+	assert.False(t, product.Variants[1].PriceInherited)
+}
+
+func TestApplyCurrency(t *testing.T) {
+	tests := []struct {
+		name          string
+		currency      string
+		sales         []SalePrice
+		variantSales  []SalePrice
+		price         string
+		original      string
+		discount      int
+		variantPrices []string
+		err           string
+	}{
+		{
+			name:          "base currency",
+			currency:      "EUR",
+			price:         "10.00",
+			variantPrices: []string{"10.00", "12.00"},
+		},
+		{
+			name:          "price list currency",
+			currency:      "GBP",
+			price:         "8.00",
+			variantPrices: []string{"8.00", "9.50"},
+		},
+		{
+			name:          "product sale in the currency",
+			currency:      "GBP",
+			sales:         []SalePrice{{Currency: "EUR", Price: dec("5.00")}, {Currency: "GBP", Price: dec("6.00")}},
+			price:         "6.00",
+			original:      "8.00",
+			discount:      25,
+			variantPrices: []string{"6.00", "9.50"},
+		},
+		{
+			name:          "variant sale",
+			currency:      "EUR",
+			variantSales:  []SalePrice{{Currency: "EUR", Price: dec("9.00")}},
+			price:         "10.00",
+			variantPrices: []string{"10.00", "9.00"},
+		},
+		{
+			name:     "no price in the currency",
+			currency: "USD",
+			err:      "no price in the requested currency: product PROD001 has no USD price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := pricedProduct()
+			product.Sales = tt.sales
+			product.Variants[1].Sales = tt.variantSales
+
+			err := product.ApplyCurrency(tt.currency)
+
+			if tt.err != "" {
+				assert.ErrorIs(t, err, ErrNoPrice)
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			product.ApplyVariantPrices()
+
+			assert.Equal(t, tt.currency, product.Currency)
+			assert.True(t, product.Price.Equal(dec(tt.price)), "price %s", product.Price)
+			if tt.original == "" {
+				assert.Nil(t, product.OriginalPrice)
+			} else {
+				assert.True(t, product.OriginalPrice.Equal(dec(tt.original)))
+			}
+			assert.Equal(t, tt.discount, product.DiscountPercent)
+			for i, price := range tt.variantPrices {
+				assert.Equal(t, tt.currency, product.Variants[i].Currency)
+				assert.True(t, product.Variants[i].EffectivePrice.Equal(dec(price)), "variant %d price %s", i, product.Variants[i].EffectivePrice)
+			}
+		})
+	}
+
+	t.Run("a variant with its own price needs it in the currency", func(t *testing.T) {
+		product := pricedProduct()
+		product.Variants[1].Prices = nil
+
+		err := product.ApplyCurrency("GBP")
+
+		assert.ErrorIs(t, err, ErrNoPrice)
+		assert.EqualError(t, err, "no price in the requested currency: variant SKU001B has no GBP price")
+	})
+
+	t.Run("applying again starts from the stored prices", func(t *testing.T) {
+		product := pricedProduct()
+		product.Sales = []SalePrice{{Currency: "EUR", Price: dec("7.50")}, {Currency: "GBP", Price: dec("6.00")}}
+
+		assert.NoError(t, product.ApplyCurrency("GBP"))
+		assert.NoError(t, product.ApplyCurrency("GBP"))
+		product.ApplyVariantPrices()
+
+		assert.True(t, product.Price.Equal(dec("6.00")))
+		assert.True(t, product.OriginalPrice.Equal(dec("8.00")))
+		assert.True(t, product.Variants[1].Price.Decimal.Equal(dec("9.50")))
+
+		assert.NoError(t, product.ApplyCurrency("EUR"))
+		product.ApplyVariantPrices()
+
+		assert.True(t, product.Price.Equal(dec("7.50")))
+		assert.True(t, product.OriginalPrice.Equal(dec("10.00")))
+		assert.Equal(t, 25, product.DiscountPercent)
+		assert.True(t, product.Variants[0].EffectivePrice.Equal(dec("7.50")))
+		assert.True(t, product.Variants[1].EffectivePrice.Equal(dec("12.00")))
+	})
+}
+
+func TestApplyProductCurrency(t *testing.T) {
+	t.Run("resolves an inheriting variant from its product", func(t *testing.T) {
+		product := pricedProduct()
+		product.Sales = []SalePrice{{Currency: "GBP", Price: dec("6.00")}}
+		variant := Variant{SKU: "SKU001A", Product: product}
+
+		assert.NoError(t, variant.ApplyProductCurrency("GBP"))
+		assert.NoError(t, variant.ApplyProductCurrency("GBP"))
+
+		assert.Equal(t, "GBP", variant.Currency)
+		assert.True(t, variant.EffectivePrice.Equal(dec("6.00")))
+		assert.True(t, variant.PriceInherited)
+		assert.True(t, variant.OriginalPrice.Equal(dec("8.00")))
+		assert.Equal(t, 25, variant.DiscountPercent)
+	})
+
+	t.Run("fails without a product price in the currency", func(t *testing.T) {
+		variant := Variant{SKU: "SKU001A", Product: pricedProduct()}
+
+		err := variant.ApplyProductCurrency("CHF")
+
+		assert.ErrorIs(t, err, ErrNoPrice)
+	})
+
+	t.Run("does nothing without a loaded product", func(t *testing.T) {
+		variant := Variant{SKU: "SKU001A", Price: nullDec("12.00")}
+
+		assert.NoError(t, variant.ApplyProductCurrency("GBP"))
+		assert.True(t, variant.Price.Decimal.Equal(dec("12.00")))
+	})
+}
+
+func TestApplySale(t *testing.T) {
+	tests := []struct {
+		name     string
+		own      decimal.NullDecimal
+		sales    []SalePrice
+		price    string
+		original string
+		discount int
+	}{
+		{"no sale", nullDec("12.00"), nil, "12.00", "", 0},
+		{"sale in another currency", nullDec("12.00"), []SalePrice{{Currency: "GBP", Price: dec("6.00")}}, "12.00", "", 0},
+		{"sale on the own price", nullDec("12.00"), []SalePrice{{Currency: "EUR", Price: dec("9.00")}}, "9.00", "12.00", 25},
+		{"sale on the inherited price", decimal.NullDecimal{}, []SalePrice{{Currency: "EUR", Price: dec("5.00")}}, "5.00", "10.00", 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := Variant{Price: tt.own, Currency: BaseCurrency, Sales: tt.sales}
+
+			variant.applySale(dec("10.00"))
+			variant.ApplyProductPrice(&Product{Price: dec("10.00")})
+
+			assert.True(t, variant.EffectivePrice.Equal(dec(tt.price)), "price %s", variant.EffectivePrice)
+			assert.Equal(t, tt.own, variant.Price, "the own price is kept")
+			assert.Equal(t, !tt.own.Valid, variant.PriceInherited)
+			if tt.original == "" {
+				assert.Nil(t, variant.OriginalPrice)
+			} else {
+				assert.True(t, variant.OriginalPrice.Equal(dec(tt.original)))
+			}
+			assert.Equal(t, tt.discount, variant.DiscountPercent)
+		})
+	}
+
+	t.Run("product sale keeps the list price as original", func(t *testing.T) {
+		product := &Product{Price: dec("40.00"), Sales: []SalePrice{{Currency: "EUR", Price: dec("30.00")}}}
+
+		product.applySale(dec("40.00"))
+
+		assert.True(t, product.Price.Equal(dec("30.00")))
+		assert.True(t, product.OriginalPrice.Equal(dec("40.00")))
+		assert.Equal(t, 25, product.DiscountPercent)
+	})
+}
@@ -4,19 +4,48 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // Product represents a product in the catalog.
-// It includes a unique code and a price.
+// It includes a unique code, a price and the descriptive attributes shown in the storefront.
+// Status, PublishAt and UnpublishAt decide whether the storefront shows it.
+// Category is the primary category; Categories lists every category the product is assigned to,
+// including the primary one.
 type Product struct {
-	ID         uint            `gorm:"primaryKey"`
-	Code       string          `gorm:"uniqueIndex;not null"`
-	Price      decimal.Decimal `gorm:"type:decimal(10,2);not null"`
-	CategoryID *uint           `gorm:"index"`
-	Category   *Category       `gorm:"foreignKey:CategoryID"`
-	Variants   []Variant       `gorm:"foreignKey:ProductID"`
-	CreatedAt  time.Time       `gorm:"autoCreateTime"`
-	UpdatedAt  time.Time       `gorm:"autoUpdateTime"`
+	ID          uint            `gorm:"primaryKey"`
+	Code        string          `gorm:"uniqueIndex;not null"`
+	Name        string          `gorm:"not null"`
+	Description string          `gorm:"not null"`
+	Brand       string          `gorm:"not null"`
+	Price       decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+	Status      string          `gorm:"not null;default:draft"`
+	PublishAt   *time.Time      `gorm:"null"`
+	UnpublishAt *time.Time      `gorm:"null"`
+	Prices      []ProductPrice  `gorm:"foreignKey:ProductID" json:"-"`
+	Sales       []SalePrice     `gorm:"foreignKey:ProductID" json:"-"`
+	CategoryID  *uint           `gorm:"index"`
+	Category    *Category       `gorm:"foreignKey:CategoryID"`
+	Categories  []Category      `gorm:"many2many:product_categories"`
+	Options     []ProductOption `gorm:"foreignKey:ProductID"`
+	Variants    []Variant       `gorm:"foreignKey:ProductID"`
+	Images      []ProductImage  `gorm:"foreignKey:ProductID"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt  `gorm:"index"`
+
+	// Breadcrumbs is the path from the root category down to Category.
+	Breadcrumbs []Breadcrumb `gorm:"-" json:",omitempty"`
+	// InStock is true when any variant is in stock; it is never persisted.
+	InStock bool `gorm:"-"`
+	// Currency is the currency of Price, set by ApplyCurrency or ApplyVariantPrices.
+	Currency string `gorm:"-"`
+	// OriginalPrice and DiscountPercent are set while a sale is active; Price is then the sale price.
+	OriginalPrice   *decimal.Decimal `gorm:"-" json:",omitempty"`
+	DiscountPercent int              `gorm:"-" json:",omitempty"`
+
+	// basePrice keeps the stored Price while Price holds a converted or sale price.
+	basePrice *decimal.Decimal
 }
 
 func (p *Product) TableName() string {
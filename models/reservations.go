@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Reservation statuses. Only active reservations hold stock.
+const (
+	ReservationActive    = "active"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation holds a quantity of a variant in a warehouse while a checkout completes.
+// Confirming it takes the quantity out of stock; releasing or expiring it frees the quantity.
+type Reservation struct {
+	ID        uint      `gorm:"primaryKey"`
+	VariantID uint      `gorm:"not null" json:"-"`
+	SKU       string    `gorm:"->"`
+	Warehouse string    `gorm:"not null"`
+	Quantity  int       `gorm:"not null"`
+	Status    string    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (r *Reservation) TableName() string {
+	return "stock_reservations"
+}
@@ -0,0 +1,28 @@
+package models
+
+import "slices"
+
+// Product statuses. Only published products are shown in the public catalog, and only
+// between their PublishAt and UnpublishAt times when those are set.
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// ProductStatuses lists every product status.
+var ProductStatuses = []string{StatusDraft, StatusPublished, StatusArchived}
+
+// statusTransitions lists the statuses a product can move to from each status. Archived
+// products go back to draft before they can be published again.
+var statusTransitions = map[string][]string{
+	StatusDraft:     {StatusPublished, StatusArchived},
+	StatusPublished: {StatusDraft, StatusArchived},
+	StatusArchived:  {StatusDraft},
+}
+
+// CanTransition reports whether a product can move from one status to another. Keeping the
+// status is always allowed, e.g. to reschedule a published product.
+func CanTransition(from, to string) bool {
+	return from == to || slices.Contains(statusTransitions[from], to)
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// DefaultWarehouse is the warehouse stock is kept in unless another one is given.
+const DefaultWarehouse = "default"
+
+// VariantStock is the quantity of a variant on hand in a warehouse.
+type VariantStock struct {
+	VariantID uint      `gorm:"primaryKey" json:"-"`
+	Warehouse string    `gorm:"primaryKey"`
+	Quantity  int       `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (s *VariantStock) TableName() string {
+	return "variant_stock"
+}
+
+// ApplyAvailability sets the quantity of the variant that can be sold.
+func (v *Variant) ApplyAvailability(available int) {
+	v.Available = available
+	v.InStock = available > 0
+}
+
+// ApplyVariantAvailability marks the product in stock when any of its variants is.
+// Variant availability must be applied first.
+func (p *Product) ApplyVariantAvailability() {
+	p.InStock = false
+	for _, variant := range p.Variants {
+		if variant.InStock {
+			p.InStock = true
+			break
+		}
+	}
+}
@@ -4,19 +4,48 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // Variant represents a product variant in the catalog.
 // It includes a unique name, SKU, and an optional price.
 // Variants can be used to represent different configurations or options for a product.
+// A variant without a price (NULL) inherits the price of its product.
+// Options holds the variant's value for each option of its product, in the product's option order.
 type Variant struct {
-	ID        uint            `gorm:"primaryKey"`
-	ProductID uint            `gorm:"not null"`
-	Name      string          `gorm:"not null"`
-	SKU       string          `gorm:"uniqueIndex;not null"`
-	Price     decimal.Decimal `gorm:"type:decimal(10,2);null"`
-	CreatedAt time.Time       `gorm:"autoCreateTime"`
-	UpdatedAt time.Time       `gorm:"autoUpdateTime"`
+	ID        uint                `gorm:"primaryKey"`
+	ProductID uint                `gorm:"not null"`
+	Product   *Product            `gorm:"foreignKey:ProductID" json:",omitempty"`
+	Name      string              `gorm:"not null"`
+	SKU       string              `gorm:"uniqueIndex;not null"`
+	Price     decimal.NullDecimal `gorm:"type:decimal(10,2);null"`
+	Prices    []VariantPrice      `gorm:"foreignKey:VariantID" json:"-"`
+	Sales     []SalePrice         `gorm:"foreignKey:VariantID" json:"-"`
+	Options   []VariantOption     `gorm:"foreignKey:VariantID"`
+	CreatedAt time.Time           `gorm:"autoCreateTime"`
+	UpdatedAt time.Time           `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt      `gorm:"index"`
+
+	// OptionSignature identifies the option combination; no two variants of a product share it.
+	OptionSignature *string `gorm:"null" json:"-"`
+
+	// EffectivePrice and PriceInherited are computed by ApplyProductPrice and never persisted.
+	// Currency is the currency of Price and EffectivePrice.
+	EffectivePrice decimal.Decimal `gorm:"-"`
+	PriceInherited bool            `gorm:"-"`
+	Currency       string          `gorm:"-"`
+	// OriginalPrice and DiscountPercent are set while a sale applies to EffectivePrice.
+	OriginalPrice   *decimal.Decimal `gorm:"-" json:",omitempty"`
+	DiscountPercent int              `gorm:"-" json:",omitempty"`
+
+	// Available and InStock are loaded from the stock across warehouses and never persisted.
+	Available int  `gorm:"-"`
+	InStock   bool `gorm:"-"`
+
+	// basePrice keeps the stored Price while Price holds a converted price.
+	basePrice *decimal.NullDecimal
+	// salePrice is the active sale of the variant itself, which becomes its EffectivePrice.
+	salePrice *decimal.Decimal
 }
 
 func (v *Variant) TableName() string {